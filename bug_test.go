@@ -2,19 +2,32 @@ package bug
 
 import (
 	"context"
+	stdsql "database/sql"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"entgo.io/bug/ent/hook"
+	"entgo.io/bug/ent/migrate"
 	"entgo.io/bug/ent/post"
+	"entgo.io/bug/ent/predicate"
 	"entgo.io/bug/ent/user"
 	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
-	_ "github.com/go-sql-driver/mysql"
-	_ "github.com/lib/pq"
-	_ "github.com/mattn/go-sqlite3"
+	"entgo.io/ent/dialect/sql/schema"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"entgo.io/bug/ent"
 	"entgo.io/bug/ent/enttest"
@@ -97,3 +110,1471 @@ func test(t *testing.T, client *ent.Client) {
 	require.Equal(t, a.ID, usersByPost[1].ID)
 	require.Equal(t, c.ID, usersByPost[2].ID)
 }
+
+func TestQueryModify(t *testing.T) {
+	client := enttest.Open(t, dialect.SQLite, "file:modify?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	a := client.User.Create().SetName("A").SaveX(ctx)
+	b := client.User.Create().SetName("B").SaveX(ctx)
+	c := client.User.Create().SetName("C").SaveX(ctx)
+	for u, n := range map[*ent.User]int{a: 5, b: 10, c: 2} {
+		for i := 0; i < n; i++ {
+			client.Post.Create().SetName(fmt.Sprintf("%s-%d", u.Name, i)).SetCreator(u).ExecX(ctx)
+		}
+	}
+
+	// Columns that are added by modifiers are read using Value.
+	users := client.User.Query().Modify(func(selector *sql.Selector) {
+		ptbl := sql.Table(post.Table)
+		subQuery := sql.Select("COUNT(*) AS c", ptbl.C(post.FieldUserID)).
+			From(ptbl).
+			GroupBy(ptbl.C(post.FieldUserID)).
+			As("ord")
+
+		selector.Join(subQuery).On(selector.C(user.FieldID), subQuery.C(post.FieldUserID))
+		selector.AppendSelect(sql.As(subQuery.C("c"), "posts_count"))
+		selector.OrderBy(sql.Desc(subQuery.C("c")))
+	}).AllX(ctx)
+	require.Len(t, users, 3)
+	for i, u := range []*ent.User{b, a, c} {
+		require.Equal(t, u.ID, users[i].ID)
+	}
+	for i, n := range []int64{10, 5, 2} {
+		v, err := users[i].Value("posts_count")
+		require.NoError(t, err)
+		require.Equal(t, n, v)
+	}
+	_, err := a.Value("posts_count")
+	require.Error(t, err)
+}
+
+func TestSoftDelete(t *testing.T) {
+	client := enttest.Open(t, dialect.SQLite, "file:softdelete?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	a := client.User.Create().SetName("A").SaveX(ctx)
+	b := client.User.Create().SetName("B").SaveX(ctx)
+	posts := client.Post.CreateBulk(
+		client.Post.Create().SetName("a-0").SetCreator(a),
+		client.Post.Create().SetName("a-1").SetCreator(a),
+		client.Post.Create().SetName("a-2").SetCreator(a),
+		client.Post.Create().SetName("b-0").SetCreator(b),
+		client.Post.Create().SetName("b-1").SetCreator(b),
+	).SaveX(ctx)
+
+	// Deleting posts only marks them as deleted.
+	n := client.Post.Delete().Where(post.NameHasPrefix("a-"), post.NameNEQ("a-0")).ExecX(ctx)
+	require.Equal(t, 2, n)
+	require.Equal(t, 3, client.Post.Query().CountX(ctx))
+	require.Equal(t, 5, client.Post.Query().IncludeDeleted().CountX(ctx))
+	require.True(t, ent.IsNotFound(client.Post.DeleteOne(posts[1]).Exec(ctx)), "already deleted")
+
+	// Edge loading and edge-count orderings ignore deleted posts.
+	users := client.User.Query().Order(user.ByPostsCount(true)).WithPosts().AllX(ctx)
+	require.Len(t, users, 2)
+	require.Equal(t, b.ID, users[0].ID)
+	require.Len(t, users[0].Edges.Posts, 2)
+	require.Equal(t, a.ID, users[1].ID)
+	require.Len(t, users[1].Edges.Posts, 1)
+	require.Equal(t, 1, a.QueryPosts().CountX(ctx))
+	require.Equal(t, 3, a.QueryPosts().IncludeDeleted().CountX(ctx))
+	// Edge predicates ignore deleted posts.
+	require.Equal(t, []int{a.ID}, client.User.Query().Where(user.HasPostsWith(post.NameHasPrefix("a-"))).IDsX(ctx))
+	require.Empty(t, client.User.Query().Where(user.HasPostsWith(post.Name("a-1"))).IDsX(ctx))
+	client.Post.Delete().Where(post.NameHasPrefix("b-")).ExecX(ctx)
+	require.Equal(t, []int{a.ID}, client.User.Query().Where(user.HasPosts()).IDsX(ctx))
+
+	// Users with posts can be deleted, as the rows are kept.
+	client.User.DeleteOne(a).ExecX(ctx)
+	_, err := client.User.Get(ctx, a.ID)
+	require.True(t, ent.IsNotFound(err))
+	deleted := client.User.Query().Where(user.ID(a.ID)).IncludeDeleted().OnlyX(ctx)
+	require.NotNil(t, deleted.DeletedAt)
+	require.Equal(t, []int{b.ID}, client.User.Query().IDsX(ctx))
+	require.Zero(t, client.Post.Query().Where(post.HasCreator()).CountX(ctx))
+	require.Equal(t, 2, client.Post.Query().IncludeDeleted().Where(post.HasCreatorWith(user.Name("B"))).CountX(ctx))
+}
+
+func TestQueryClone(t *testing.T) {
+	var queries []string
+	client := enttest.Open(t, dialect.SQLite, "file:clone?mode=memory&cache=shared&_fk=1",
+		enttest.WithOptions(ent.Log(func(args ...any) {
+			queries = append(queries, fmt.Sprint(args...))
+		})),
+	)
+	defer client.Close()
+	ctx := context.Background()
+
+	a := client.User.Create().SetName("A").SaveX(ctx)
+	b := client.User.Create().SetName("B").SaveX(ctx)
+	client.Post.Create().SetName("a-0").SetCreator(a).ExecX(ctx)
+	client.User.DeleteOne(b).ExecX(ctx)
+
+	// Clones keep the configuration of the query, and do not share its steps.
+	q := client.User.Query().IncludeDeleted().Where(user.NameIn("A", "B"))
+	clone := q.Clone()
+	q.Where(user.Name("A"))
+	require.Equal(t, 2, clone.CountX(ctx))
+	require.Equal(t, 1, q.CountX(ctx))
+	v := client.User.Query().Modify(func(s *sql.Selector) {
+		s.Where(sql.EQ(s.C(user.FieldName), "A"))
+	}).UserQuery.Clone().Select(user.FieldName).StringsX(ctx)
+	require.Equal(t, []string{"A"}, v)
+	_, err := client.User.Query().Timeout(time.Nanosecond).Clone().All(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	tx, err := client.Tx(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	_, err = tx.User.Query().ForUpdate().Clone().All(ctx)
+	require.Error(t, err, "row locks are not supported by SQLite")
+
+	queries = queries[:0]
+	posts := client.Debug().Post.Query().EagerJoin().WithCreator().Clone().AllX(ctx)
+	require.Len(t, queries, 1)
+	require.Equal(t, a.ID, posts[0].Edges.Creator.ID)
+}
+
+func TestCascadeDelete(t *testing.T) {
+	require.Equal(t, schema.Cascade, migrate.PostsTable.ForeignKeys[0].OnDelete)
+
+	migrated := enttest.Open(t, dialect.SQLite, "file:cascade?mode=memory&cache=shared&_fk=1")
+	defer migrated.Close()
+	// Foreign-keys are not enforced on connections opened without "_fk=1".
+	client, err := ent.Open(dialect.SQLite, "file:cascade?mode=memory&cache=shared")
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	a := client.User.Create().SetName("A").SaveX(ctx)
+	b := client.User.Create().SetName("B").SaveX(ctx)
+	client.Post.Create().SetName("a-0").SetCreator(a).ExecX(ctx)
+	client.Post.Create().SetName("a-1").SetCreator(a).ExecX(ctx)
+	client.Post.Create().SetName("b-0").SetCreator(b).ExecX(ctx)
+
+	client.User.DeleteOne(a).Cascade().ExecX(ctx)
+	require.Zero(t, client.Post.Query().Where(post.UserID(a.ID)).CountX(ctx))
+	require.Equal(t, 2, client.Post.Query().Where(post.UserID(a.ID)).IncludeDeleted().CountX(ctx))
+	require.Equal(t, 1, client.Post.Query().CountX(ctx))
+
+	n := client.User.Delete().Where(user.Name("A")).Cascade().ExecX(ctx)
+	require.Zero(t, n, "user was already deleted")
+}
+
+func TestReassignDelete(t *testing.T) {
+	client := enttest.Open(t, dialect.SQLite, "file:reassign?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	ghost := client.User.Create().SetName("ghost").SaveX(ctx)
+	a := client.User.Create().SetName("A").SaveX(ctx)
+	client.Post.Create().SetName("a-0").SetCreator(a).ExecX(ctx)
+	client.Post.Create().SetName("a-1").SetCreator(a).ExecX(ctx)
+
+	var moved []int
+	client.Post.Use(func(next ent.Mutator) ent.Mutator {
+		return hook.PostFunc(func(ctx context.Context, m *ent.PostMutation) (ent.Value, error) {
+			if id, ok := m.UserID(); ok {
+				moved = append(moved, id)
+			}
+			return next.Mutate(ctx, m)
+		})
+	})
+	_, err := client.User.DeleteOne(a).ReassignPosts(ctx, a.ID)
+	require.Error(t, err)
+	n, err := client.User.DeleteOne(a).ReassignPosts(ctx, ghost.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []int{ghost.ID}, moved)
+	require.Equal(t, 2, ghost.QueryPosts().CountX(ctx))
+	require.False(t, client.User.Query().Where(user.ID(a.ID)).ExistX(ctx))
+
+	// Reassigning to a deleted user fails, and nothing is changed.
+	b := client.User.Create().SetName("B").SaveX(ctx)
+	client.Post.Create().SetName("b-0").SetCreator(b).ExecX(ctx)
+	_, err = client.User.DeleteOne(b).ReassignPosts(ctx, a.ID)
+	require.True(t, ent.IsNotFound(err))
+	require.Equal(t, 1, b.QueryPosts().CountX(ctx))
+	require.True(t, client.User.Query().Where(user.ID(b.ID)).ExistX(ctx))
+
+	// The mutation of the builder is not bound to the transaction of the reassignment.
+	client.User.Use(func(next ent.Mutator) ent.Mutator {
+		return hook.UserFunc(func(ctx context.Context, m *ent.UserMutation) (ent.Value, error) {
+			if _, err := m.Client().User.Query().Count(ctx); err != nil {
+				return nil, err
+			}
+			return next.Mutate(ctx, m)
+		})
+	})
+	del := client.User.DeleteOne(b)
+	_, err = del.ReassignPosts(ctx, ghost.ID)
+	require.NoError(t, err)
+	require.True(t, ent.IsNotFound(del.Exec(ctx)))
+}
+
+func TestUpsert(t *testing.T) {
+	const dsn = "file:upsert?mode=memory&cache=shared&_fk=1"
+	client := enttest.Open(t, dialect.SQLite, dsn)
+	defer client.Close()
+	ctx := context.Background()
+
+	// The schema has no unique fields besides the ID, so conflicts are raised on a user-defined index.
+	drv, err := sql.Open(dialect.SQLite, dsn)
+	require.NoError(t, err)
+	defer drv.Close()
+	require.NoError(t, drv.Exec(ctx, "CREATE UNIQUE INDEX user_name ON users (name)", []any{}, nil))
+
+	a := client.User.Create().SetName("A").SaveX(ctx)
+	id := client.User.Create().SetName("A").OnConflictColumns(user.FieldName).Ignore().IDX(ctx)
+	require.Equal(t, a.ID, id)
+
+	users := client.User.CreateBulk(
+		client.User.Create().SetName("B"),
+		client.User.Create().SetName("A"),
+		client.User.Create().SetName("C"),
+	).OnConflictColumns(user.FieldName).UpdateNewValues().SaveX(ctx)
+	require.Equal(t, a.ID, users[1].ID)
+	for _, u := range users {
+		require.Equal(t, u.Name, client.User.GetX(ctx, u.ID).Name)
+	}
+
+	// Rows skipped by DO NOTHING are left without an ID.
+	users = client.User.CreateBulk(
+		client.User.Create().SetName("C"),
+		client.User.Create().SetName("D"),
+	).OnConflictColumns(user.FieldName).DoNothing().SaveX(ctx)
+	require.Zero(t, users[0].ID)
+	require.Equal(t, "D", client.User.GetX(ctx, users[1].ID).Name)
+	require.Equal(t, 4, client.User.Query().CountX(ctx))
+}
+
+func TestCreateBulkBatches(t *testing.T) {
+	const dsn = "file:batches?mode=memory&cache=shared&_fk=1"
+	client := enttest.Open(t, dialect.SQLite, dsn)
+	defer client.Close()
+	ctx := context.Background()
+
+	// Each post binds 2 parameters, which exceeds the SQLite limit.
+	const n = 20000
+	a := client.User.Create().SetName("A").SaveX(ctx)
+	builders := make([]*ent.PostCreate, n)
+	for i := range builders {
+		builders[i] = client.Post.Create().SetName(strconv.Itoa(i)).SetCreator(a)
+	}
+	posts := client.Post.CreateBulk(builders...).SaveX(ctx)
+	require.Len(t, posts, n)
+	for i := 1; i < n; i++ {
+		require.Greater(t, posts[i].ID, posts[i-1].ID)
+	}
+	require.Equal(t, strconv.Itoa(n-1), client.Post.GetX(ctx, posts[n-1].ID).Name)
+	require.Equal(t, n, a.QueryPosts().CountX(ctx))
+
+	// A failure in the last batch undoes the previous ones only if InTx was set.
+	drv, err := sql.Open(dialect.SQLite, dsn)
+	require.NoError(t, err)
+	defer drv.Close()
+	require.NoError(t, drv.Exec(ctx, "CREATE UNIQUE INDEX post_name ON posts (name)", []any{}, nil))
+	for i := range builders[:n-1] {
+		builders[i] = client.Post.Create().SetName(fmt.Sprintf("new-%d", i)).SetCreator(a)
+	}
+	builders[n-1] = client.Post.Create().SetName("0").SetCreator(a)
+	err = client.Post.CreateBulk(builders...).InTx().Exec(ctx)
+	require.True(t, ent.IsConstraintError(err))
+	var berr *ent.BatchError
+	require.False(t, errors.As(err, &berr))
+	require.Equal(t, n, a.QueryPosts().CountX(ctx))
+	// Otherwise, the failed batch is reported, and the inserted posts are returned.
+	created, err := client.Post.CreateBulk(builders...).Save(ctx)
+	require.True(t, ent.IsConstraintError(err))
+	require.ErrorAs(t, err, &berr)
+	require.Positive(t, berr.Batch)
+	require.Equal(t, n, berr.End)
+	require.Len(t, created, berr.Start)
+	require.Equal(t, n+berr.Start, a.QueryPosts().CountX(ctx))
+	require.Equal(t, fmt.Sprintf("new-%d", berr.Start-1), client.Post.GetX(ctx, created[berr.Start-1].ID).Name)
+}
+
+func TestQueryIter(t *testing.T) {
+	client := enttest.Open(t, dialect.SQLite, "file:iter?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	a := client.User.Create().SetName("A").SaveX(ctx)
+	for i := 0; i < 5; i++ {
+		client.Post.Create().SetName(strconv.Itoa(i)).SetCreator(a).ExecX(ctx)
+	}
+	client.Post.Delete().Where(post.Name("0")).ExecX(ctx)
+
+	it, err := a.QueryPosts().Where(post.NameNEQ("4")).Order(ent.Desc(post.FieldName)).Iter(ctx)
+	require.NoError(t, err)
+	var names []string
+	for it.Next() {
+		require.Equal(t, a.ID, it.Value().UserID)
+		names = append(names, it.Value().Name)
+	}
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close())
+	require.Equal(t, []string{"3", "2", "1"}, names)
+
+	// Each stops on the first error.
+	var n int
+	stop := fmt.Errorf("stop")
+	err = client.Post.Query().Select(post.FieldName).Each(ctx, func(p *ent.Post) error {
+		require.NotZero(t, p.ID)
+		require.Zero(t, p.UserID)
+		if n++; n == 2 {
+			return stop
+		}
+		return nil
+	})
+	require.Equal(t, stop, err)
+	require.Equal(t, 2, n)
+
+	_, err = client.User.Query().WithPosts().Iter(ctx)
+	require.Error(t, err)
+}
+
+func TestForEachBatch(t *testing.T) {
+	client := enttest.Open(t, dialect.SQLite, "file:batch?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 7; i++ {
+		client.User.Create().SetName(strconv.Itoa(i)).ExecX(ctx)
+	}
+	// Deleting the processed users does not skip pages.
+	var sizes []int
+	err := client.User.Query().ForEachBatch(ctx, 3, func(users []*ent.User) error {
+		sizes = append(sizes, len(users))
+		for _, u := range users {
+			client.User.DeleteOne(u).ExecX(ctx)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 3, 1}, sizes)
+	require.Zero(t, client.User.Query().CountX(ctx))
+
+	// Each page is committed separately, and processing stops on the first error.
+	stop := fmt.Errorf("stop")
+	sizes = sizes[:0]
+	err = client.User.Query().IncludeDeleted().ForEachBatchTx(ctx, 3, func(tx *ent.Tx, users []*ent.User) error {
+		sizes = append(sizes, len(users))
+		for _, u := range users {
+			tx.User.UpdateOne(u).ClearDeletedAt().ExecX(ctx)
+		}
+		if len(sizes) == 2 {
+			return stop
+		}
+		return nil
+	})
+	require.Equal(t, stop, err)
+	require.Equal(t, []int{3, 3}, sizes)
+	require.Equal(t, 3, client.User.Query().CountX(ctx))
+
+	// Edges are eager-loaded in the transaction of the page, and the query is not changed.
+	for _, u := range client.User.Query().AllX(ctx) {
+		client.Post.Create().SetName(u.Name).SetCreator(u).ExecX(ctx)
+	}
+	drv, err := sql.Open(dialect.SQLite, "file:batch?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+	defer drv.Close()
+	var loads []string
+	debug := ent.NewClient(ent.Driver(dialect.DebugWithContext(drv, func(_ context.Context, v ...any) {
+		if s := fmt.Sprint(v...); strings.Contains(s, "FROM `posts`") {
+			loads = append(loads, s)
+		}
+	})))
+	query := debug.User.Query().WithPosts()
+	err = query.ForEachBatchTx(ctx, 2, func(tx *ent.Tx, users []*ent.User) error {
+		for _, u := range users {
+			require.Len(t, u.Edges.Posts, 1)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, loads, 2)
+	for _, l := range loads {
+		require.True(t, strings.HasPrefix(l, "Tx("), l)
+	}
+	require.Len(t, query.AllX(ctx), 3)
+
+	require.Error(t, client.User.Query().Limit(1).ForEachBatch(ctx, 3, func([]*ent.User) error { return nil }))
+}
+
+func TestLoader(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries int
+	)
+	const dsn = "file:loader?mode=memory&cache=shared&_fk=1"
+	client := enttest.Open(t, dialect.SQLite, dsn)
+	defer client.Close()
+	ctx := context.Background()
+
+	users := client.User.CreateBulk(
+		client.User.Create().SetName("A"),
+		client.User.Create().SetName("B"),
+		client.User.Create().SetName("C"),
+	).SaveX(ctx)
+	for i, u := range users {
+		for j := 0; j < i+1; j++ {
+			client.Post.Create().SetName(fmt.Sprintf("%s-%d", u.Name, j)).SetCreator(u).ExecX(ctx)
+		}
+	}
+	posts := client.Post.Query().AllX(ctx)
+
+	drv, err := sql.Open(dialect.SQLite, dsn)
+	require.NoError(t, err)
+	defer drv.Close()
+	debug := ent.NewClient(ent.Driver(dialect.DebugWithContext(drv, func(context.Context, ...any) {
+		mu.Lock()
+		queries++
+		mu.Unlock()
+	})))
+	// Batches are flushed only when they are full, so they do not depend on timing.
+	loader := ent.NewLoader(debug, ent.LoaderWait(time.Hour), ent.LoaderMaxBatch(len(users)))
+	lctx := ent.NewLoaderContext(ctx, loader)
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, 2*(2*len(users)+len(posts)))
+	)
+	check := func(err error, ok bool, format string, args ...any) {
+		switch {
+		case err != nil:
+			errs <- err
+		case !ok:
+			errs <- fmt.Errorf(format, args...)
+		}
+	}
+	counts := map[string]int{"A": 1, "B": 2, "C": 3}
+	for i := 0; i < 2; i++ {
+		for _, u := range users {
+			wg.Add(2)
+			go func(u *ent.User) {
+				defer wg.Done()
+				posts, err := u.LoadPosts(lctx)
+				check(err, len(posts) == counts[u.Name], "user %s: %d posts", u.Name, len(posts))
+			}(u)
+			go func(u *ent.User) {
+				defer wg.Done()
+				n, err := u.LoadPostsCount(lctx)
+				check(err, n == counts[u.Name], "user %s: posts count %d", u.Name, n)
+			}(u)
+		}
+		for _, p := range posts {
+			wg.Add(1)
+			go func(p *ent.Post) {
+				defer wg.Done()
+				u, err := p.LoadCreator(lctx)
+				check(err, err == nil && u.ID == p.UserID, "post %d: creator mismatch", p.ID)
+			}(p)
+		}
+		wg.Wait()
+	}
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	// Two queries (the nodes and their edge) for the batch of posts and for each batch of
+	// creators, and one for the batch of counts. The second round is served from the cache.
+	require.Equal(t, 2+1+2*len(posts)/len(users), queries)
+
+	// A canceled caller does not fail the other loads of its batch.
+	loader = ent.NewLoader(debug, ent.LoaderWait(time.Hour), ent.LoaderMaxBatch(2))
+	lctx = ent.NewLoaderContext(ctx, loader)
+	canceled, cancel := context.WithCancel(lctx)
+	cancel()
+	_, err = users[0].LoadPosts(canceled)
+	require.ErrorIs(t, err, context.Canceled)
+	loaded, err := users[1].LoadPosts(lctx)
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+}
+
+func TestInChunks(t *testing.T) {
+	client := enttest.Open(t, dialect.SQLite, "file:chunks?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	// More IDs than the SQLite bound-parameter limit.
+	const n = 35000
+	builders := make([]*ent.UserCreate, n)
+	for i := range builders {
+		builders[i] = client.User.Create().SetName(strconv.Itoa(i))
+	}
+	users := client.User.CreateBulk(builders...).SaveX(ctx)
+	ids := make([]int, n)
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	posts := make([]*ent.PostCreate, n)
+	for i, u := range users {
+		posts[i] = client.Post.Create().SetName(u.Name).SetCreator(u)
+	}
+	client.Post.CreateBulk(posts...).ExecX(ctx)
+
+	require.Equal(t, n, client.User.Query().Where(user.IDIn(ids...)).CountX(ctx))
+	require.Equal(t, n-1, client.Post.Query().Where(post.UserIDIn(ids[1:]...)).CountX(ctx))
+	require.Equal(t, 1, client.Post.Query().Where(post.UserIDNotIn(ids[1:]...)).CountX(ctx))
+
+	// Long lists of strings are bound as one parameter.
+	names := make([]string, n)
+	for i, u := range users {
+		names[i] = u.Name
+	}
+	matched := client.User.Query().Where(user.NameIn(names[1:]...)).Order(ent.Asc(user.FieldID)).AllX(ctx)
+	require.Len(t, matched, n-1)
+	require.Equal(t, users[1].ID, matched[0].ID)
+	require.Equal(t, users[0].ID, client.User.Query().Where(user.NameNotIn(names[1:]...)).OnlyIDX(ctx))
+	s := sql.Dialect(dialect.Postgres).Select("id").From(sql.Table(user.Table))
+	user.NameIn(append(names, names...)...)(s)
+	s.Query()
+	require.EqualError(t, s.Err(), "predicate: IN list of 70000 values exceeds the 65535 parameters of the postgres dialect")
+
+	// Long lists are split into IN lists of at most InChunkSize values.
+	size := predicate.InChunkSize(dialect.SQLite)
+	s = sql.Dialect(dialect.SQLite).Select("id").From(sql.Table(user.Table))
+	user.IDNotIn(ids...)(s)
+	query, args := s.Query()
+	require.Equal(t, n/size+1, strings.Count(query, "`id` NOT IN ("))
+	require.Equal(t, n/size, strings.Count(query, " AND "))
+	require.Empty(t, args)
+
+	for _, u := range client.User.Query().WithPosts().AllX(ctx) {
+		require.Len(t, u.Edges.Posts, 1)
+		require.Equal(t, u.Name, u.Edges.Posts[0].Name)
+	}
+	for _, p := range client.Post.Query().WithCreator().AllX(ctx) {
+		require.Equal(t, p.Name, p.Edges.Creator.Name)
+	}
+
+	// The ON DELETE actions are applied on chunks of the IDs.
+	require.Equal(t, n, client.User.Delete().Cascade().ExecX(ctx))
+	require.Zero(t, client.Post.Query().CountX(ctx))
+}
+
+func TestEagerJoin(t *testing.T) {
+	var queries []string
+	client := enttest.Open(t, dialect.SQLite, "file:eagerjoin?mode=memory&cache=shared&_fk=1",
+		enttest.WithOptions(ent.Log(func(args ...any) {
+			queries = append(queries, fmt.Sprint(args...))
+		})),
+	)
+	defer client.Close()
+	ctx := context.Background()
+
+	a := client.User.Create().SetName("A").SaveX(ctx)
+	b := client.User.Create().SetName("B").SaveX(ctx)
+	client.Post.Create().SetName("a-0").SetCreator(a).ExecX(ctx)
+	client.Post.Create().SetName("a-1").SetCreator(a).ExecX(ctx)
+	client.Post.Create().SetName("b-0").SetCreator(b).ExecX(ctx)
+	client.User.DeleteOne(b).ExecX(ctx)
+
+	debug := client.Debug()
+	posts := debug.Post.Query().EagerJoin().WithCreator().Order(ent.Asc(post.FieldName)).AllX(ctx)
+	require.Len(t, queries, 1)
+	require.Len(t, posts, 3)
+	require.Equal(t, a.ID, posts[0].Edges.Creator.ID)
+	require.Equal(t, "A", posts[1].Edges.Creator.Name)
+	// Soft-deleted creators are not loaded, like in the two-query strategy.
+	require.Nil(t, posts[2].Edges.Creator)
+	_, err := posts[2].Edges.CreatorOrErr()
+	require.True(t, ent.IsNotFound(err))
+
+	queries = queries[:0]
+	posts = debug.Post.Query().EagerJoin().WithCreator(func(q *ent.UserQuery) {
+		q.IncludeDeleted().Where(user.NameNEQ("A"))
+	}).Order(ent.Asc(post.FieldName)).AllX(ctx)
+	require.Len(t, queries, 1)
+	require.Nil(t, posts[0].Edges.Creator)
+	require.Equal(t, b.ID, posts[2].Edges.Creator.ID)
+
+	// Neighbors that eager-load their own edges fall back to a second query.
+	queries = queries[:0]
+	posts = debug.Post.Query().EagerJoin().WithCreator(func(q *ent.UserQuery) {
+		q.WithPosts()
+	}).Order(ent.Asc(post.FieldName)).AllX(ctx)
+	require.Len(t, queries, 3)
+	require.Len(t, posts[0].Edges.Creator.Edges.Posts, 2)
+}
+
+func TestQueryLock(t *testing.T) {
+	client := enttest.Open(t, dialect.SQLite, "file:lock?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	a := client.User.Create().SetName("A").SaveX(ctx)
+	tx, err := client.Tx(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	// Row locks are not supported by SQLite.
+	_, err = tx.Post.Query().Where(post.UserID(a.ID)).ForUpdate().Count(ctx)
+	require.EqualError(t, err, "ent: row-level locking is not supported by sqlite")
+	_, err = tx.User.Query().ForShare(sql.WithLockAction(sql.NoWait)).All(ctx)
+	require.EqualError(t, err, "ent: row-level locking is not supported by sqlite")
+	_, err = tx.User.Query().All(ctx)
+	require.NoError(t, err)
+
+	// Rows of counted queries are locked in a subquery.
+	db, err := stdsql.Open("sqlite3", "file:lock?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+	defer db.Close()
+	for d, want := range map[string]string{
+		dialect.MySQL:    "SELECT COUNT(*) FROM (SELECT DISTINCT `users`.`id` FROM `users` WHERE `users`.`deleted_at` IS NULL AND `users`.`name` = ? FOR UPDATE) AS `t`",
+		dialect.Postgres: `SELECT COUNT(*) FROM (SELECT "users"."id" FROM "users" WHERE "users"."deleted_at" IS NULL AND "users"."name" = $1 FOR UPDATE) AS "t"`,
+	} {
+		var stmts []string
+		client := ent.NewClient(ent.Driver(sql.OpenDB(d, db)), ent.QueryLogger(func(_ context.Context, e ent.QueryLogEntry) {
+			stmts = append(stmts, e.Statement)
+		}))
+		_, _ = client.User.Query().Where(user.Name("A")).ForUpdate().Count(ctx)
+		require.Equal(t, []string{want}, stmts, d)
+	}
+
+	// Queries that are joined for eager-loading lock only their own rows.
+	for d, want := range map[string][]string{
+		dialect.MySQL:    {"FOR UPDATE OF `posts`", "FOR SHARE OF `posts` NOWAIT", "LOCK IN SHARE MODE"},
+		dialect.Postgres: {`FOR UPDATE OF "posts"`, `FOR SHARE OF "posts" NOWAIT`, "FOR SHARE"},
+	} {
+		var stmts []string
+		client := ent.NewClient(ent.Driver(sql.OpenDB(d, db)), ent.QueryLogger(func(_ context.Context, e ent.QueryLogEntry) {
+			stmts = append(stmts, e.Statement)
+		}))
+		_, _ = client.Post.Query().EagerJoin().WithCreator().ForUpdate().All(ctx)
+		_, _ = client.Post.Query().EagerJoin().WithCreator().ForShare(sql.WithLockAction(sql.NoWait)).All(ctx)
+		_, _ = client.Post.Query().ForShare().All(ctx)
+		require.Len(t, stmts, 3, d)
+		for i, stmt := range stmts {
+			require.True(t, strings.HasSuffix(stmt, want[i]), stmt)
+		}
+		require.Contains(t, stmts[0], "LEFT JOIN", d)
+	}
+}
+
+func TestProject(t *testing.T) {
+	client := enttest.Open(t, dialect.SQLite, "file:project?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	a := client.User.Create().SetName("A").SaveX(ctx)
+	b := client.User.Create().SetName("B").SaveX(ctx)
+	client.Post.Create().SetName("a-0").SetCreator(a).ExecX(ctx)
+	client.Post.Create().SetName("a-1").SetCreator(a).ExecX(ctx)
+	p := client.Post.Create().SetName("a-2").SetCreator(a).SaveX(ctx)
+	client.Post.Create().SetName("b-0").SetCreator(b).ExecX(ctx)
+	client.Post.DeleteOne(p).ExecX(ctx)
+
+	type UserStats struct {
+		ID    int
+		Name  string
+		Posts int
+	}
+	stats := ent.Project[UserStats](client.User.Query().Order(ent.Asc(user.FieldName)),
+		ent.Field(ent.UserColumns.ID, func(s *UserStats) *int { return &s.ID }),
+		ent.Field(ent.UserColumns.Name, func(s *UserStats) *string { return &s.Name }),
+		ent.Field(ent.UserColumns.PostsCount, func(s *UserStats) *int { return &s.Posts }),
+	).AllX(ctx)
+	require.Equal(t, []UserStats{{a.ID, "A", 2}, {b.ID, "B", 1}}, stats)
+
+	type PostInfo struct {
+		Name    string
+		Creator *string
+	}
+	client.User.DeleteOne(b).ExecX(ctx)
+	infos := ent.Project[PostInfo](client.Post.Query().IncludeDeleted().Where(post.NameHasSuffix("-0")).Order(ent.Asc(post.FieldName)),
+		ent.Field(ent.PostColumns.Name, func(s *PostInfo) *string { return &s.Name }),
+		ent.Field(ent.PostColumns.Creator.Name, func(s *PostInfo) **string { return &s.Creator }),
+	).AllX(ctx)
+	require.Len(t, infos, 2)
+	require.Equal(t, "A", *infos[0].Creator)
+	// Soft-deleted creators are not joined.
+	require.Nil(t, infos[1].Creator)
+
+	_, err := ent.Project[PostInfo](client.Post.Query()).All(ctx)
+	require.Error(t, err)
+}
+
+func TestSubqueryPredicates(t *testing.T) {
+	var queries []string
+	client := enttest.Open(t, dialect.SQLite, "file:subquery?mode=memory&cache=shared&_fk=1",
+		enttest.WithOptions(ent.Log(func(args ...any) {
+			queries = append(queries, fmt.Sprint(args...))
+		})),
+	)
+	defer client.Close()
+	ctx := context.Background()
+
+	a := client.User.Create().SetName("A").SaveX(ctx)
+	b := client.User.Create().SetName("B").SaveX(ctx)
+	c := client.User.Create().SetName("C").SaveX(ctx)
+	client.Post.Create().SetName("x-0").SetCreator(a).ExecX(ctx)
+	client.Post.Create().SetName("x-1").SetCreator(a).ExecX(ctx)
+	client.Post.Create().SetName("y-0").SetCreator(b).ExecX(ctx)
+	p := client.Post.Create().SetName("x-2").SetCreator(c).SaveX(ctx)
+	client.Post.DeleteOne(p).ExecX(ctx)
+
+	debug := client.Debug()
+	ids := debug.User.Query().
+		Where(user.IDInQuery(debug.Post.Query().Where(post.NameHasPrefix("x")).SelectUserIDs())).
+		IDsX(ctx)
+	require.Len(t, queries, 1)
+	// Soft-deleted posts are skipped by the subquery.
+	require.Equal(t, []int{a.ID}, ids)
+
+	ids = client.User.Query().
+		Where(user.IDNotInQuery(client.Post.Query().Where(post.NameHasPrefix("x")).SelectUserIDs())).
+		Order(ent.Asc(user.FieldID)).
+		IDsX(ctx)
+	require.Equal(t, []int{b.ID, c.ID}, ids)
+
+	names := client.Post.Query().
+		Where(post.UserIDInQuery(client.User.Query().Where(user.Name("B")).SelectIDs())).
+		Select(post.FieldName).
+		StringsX(ctx)
+	require.Equal(t, []string{"y-0"}, names)
+	require.Len(t, client.User.Query().Where(user.IDIn(a.ID, b.ID)).AllX(ctx), 2)
+
+	// Subqueries are built with the context of the statement.
+	type ctxKey struct{}
+	var values []any
+	sub := predicate.NewSubquery[int](func(ctx context.Context) (*sql.Selector, error) {
+		values = append(values, ctx.Value(ctxKey{}))
+		return sql.Select(post.FieldUserID).From(sql.Table(post.Table)), nil
+	})
+	vctx := context.WithValue(ctx, ctxKey{}, "v")
+	n := client.User.Query().Where(user.IDInQuery(sub)).CountX(vctx)
+	require.Len(t, client.User.Query().Where(user.IDInQuery(sub)).AllX(vctx), n)
+	require.NotEmpty(t, client.User.Query().Where(user.IDInQuery(sub)).Union(client.User.Query()).AllX(vctx))
+	require.Equal(t, []any{"v", "v", "v"}, values)
+}
+
+func TestSetOperations(t *testing.T) {
+	client := enttest.Open(t, dialect.SQLite, "file:setop?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	users := client.User.CreateBulk(
+		client.User.Create().SetName("a"),
+		client.User.Create().SetName("b"),
+		client.User.Create().SetName("xc"),
+		client.User.Create().SetName("xd"),
+	).SaveX(ctx)
+	for i, u := range users[:3] {
+		for j := 0; j < 3-i; j++ {
+			client.Post.Create().SetName(strconv.Itoa(j)).SetCreator(u).ExecX(ctx)
+		}
+	}
+	client.User.DeleteOne(users[3]).ExecX(ctx)
+	names := func(us []*ent.User) []string {
+		vs := make([]string, len(us))
+		for i := range us {
+			vs[i] = us[i].Name
+		}
+		return vs
+	}
+
+	active := client.User.Query().Where(user.HasPostsWith(post.NameIn("1", "2")))
+	matched := client.User.Query().Where(user.NameHasPrefix("x"))
+	us := active.Union(matched).
+		Order(user.ByPostsCount(true)).
+		Limit(2).
+		AllX(ctx)
+	require.Equal(t, []string{"a", "b"}, names(us))
+	us = client.User.Query().Where(user.HasPostsWith(post.NameIn("1", "2"))).
+		Union(client.User.Query().IncludeDeleted().Where(user.NameHasPrefix("x"))).
+		Order(ent.Asc(user.FieldName)).
+		Offset(1).
+		WithPosts().
+		AllX(ctx)
+	require.Equal(t, []string{"b", "xc", "xd"}, names(us))
+	require.Len(t, us[0].Edges.Posts, 2)
+
+	us = client.User.Query().
+		Intersect(client.User.Query().Where(user.HasPosts()), client.User.Query().Where(user.NameNEQ("a"))).
+		Order(ent.Asc(user.FieldName)).
+		AllX(ctx)
+	require.Equal(t, []string{"b", "xc"}, names(us))
+	us = client.User.Query().
+		Except(client.User.Query().Where(user.HasPostsWith(post.Name("2")))).
+		Union(client.User.Query().Where(user.Name("a"))).
+		Order(ent.Asc(user.FieldName)).
+		AllX(ctx)
+	require.Equal(t, []string{"a", "b", "xc"}, names(us))
+	n := client.Post.Query().Where(post.NameNEQ("0")).Except(client.Post.Query().Where(post.Name("2"))).CountX(ctx)
+	require.Equal(t, 2, n)
+
+	_, err := client.User.Query().Limit(1).Union(client.User.Query()).All(ctx)
+	require.Error(t, err)
+
+	// The timeout, modifiers and eager-loading of the query apply to the result.
+	us = client.User.Query().Where(user.Name("a")).
+		WithPosts().
+		Modify(func(s *sql.Selector) { s.Where(sql.NEQ(s.C(user.FieldName), "xc")) }).
+		Union(client.User.Query().Where(user.NameIn("b", "xc"))).
+		Order(ent.Asc(user.FieldName)).
+		AllX(ctx)
+	require.Equal(t, []string{"a", "b"}, names(us))
+	require.Len(t, us[0].Edges.Posts, 3)
+	require.Len(t, us[1].Edges.Posts, 2)
+	_, err = client.User.Query().Timeout(time.Nanosecond).Union(client.User.Query()).All(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = client.User.Query().Union(client.User.Query().WithPosts()).All(ctx)
+	require.EqualError(t, err, "ent: other operands of UNION do not support Timeout, modifiers and eager-loading")
+}
+
+func TestWithTx(t *testing.T) {
+	client := enttest.Open(t, dialect.SQLite, "file:withtx?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	err := client.WithTx(ctx, func(tx *ent.Tx) error {
+		u, err := tx.User.Create().SetName("a").Save(ctx)
+		if err != nil {
+			return err
+		}
+		return tx.Post.Create().SetName("a-0").SetCreator(u).Exec(ctx)
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, client.Post.Query().CountX(ctx))
+
+	var rolledback bool
+	errFail := errors.New("fail")
+	err = client.WithTx(ctx, func(tx *ent.Tx) error {
+		tx.OnRollback(func(next ent.Rollbacker) ent.Rollbacker {
+			return ent.RollbackFunc(func(ctx context.Context, tx *ent.Tx) error {
+				rolledback = true
+				return next.Rollback(ctx, tx)
+			})
+		})
+		tx.User.Create().SetName("b").ExecX(ctx)
+		return errFail
+	}, ent.TxBeginOptions(&sql.TxOptions{Isolation: stdsql.LevelSerializable}))
+	require.ErrorIs(t, err, errFail)
+	require.True(t, rolledback)
+	require.Equal(t, 1, client.User.Query().CountX(ctx))
+
+	require.PanicsWithValue(t, "boom", func() {
+		_ = client.WithTx(ctx, func(tx *ent.Tx) error {
+			tx.User.Create().SetName("c").ExecX(ctx)
+			panic("boom")
+		})
+	})
+	require.Equal(t, 1, client.User.Query().CountX(ctx))
+}
+
+func TestWithTxRetry(t *testing.T) {
+	dsn := "file:" + t.TempDir() + "/retry.db?_fk=1&_busy_timeout=0"
+	client := enttest.Open(t, dialect.SQLite, dsn)
+	defer client.Close()
+	ctx := context.Background()
+
+	drv, err := sql.Open(dialect.SQLite, dsn)
+	require.NoError(t, err)
+	defer drv.Close()
+	// Hold the write lock of the database until the first retry.
+	lock, err := drv.DB().BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = lock.ExecContext(ctx, "INSERT INTO users (name) VALUES ('lock')")
+	require.NoError(t, err)
+
+	var attempts, retries int
+	err = client.WithTx(ctx, func(tx *ent.Tx) error {
+		attempts++
+		return tx.User.Create().SetName("a").Exec(ctx)
+	}, ent.TxRetry(3), ent.TxBackoff(func(retry int) time.Duration {
+		retries++
+		require.NoError(t, lock.Commit())
+		return time.Millisecond
+	}))
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+	require.Equal(t, 1, retries)
+	require.Equal(t, 2, client.User.Query().CountX(ctx))
+
+	// Nested transactions are retried by the outermost WithTx.
+	var outer, nested int
+	err = client.WithTx(ctx, func(tx *ent.Tx) error {
+		outer++
+		return tx.Client().WithTx(ctx, func(tx *ent.Tx) error {
+			if nested++; nested == 1 {
+				return &pq.Error{Code: "40001"}
+			}
+			return tx.User.Create().SetName("b").Exec(ctx)
+		}, ent.TxRetry(3))
+	}, ent.TxRetry(3), ent.TxBackoff(func(int) time.Duration { return time.Millisecond }))
+	require.NoError(t, err)
+	require.Equal(t, 2, outer)
+	require.Equal(t, 2, nested)
+	require.Equal(t, 3, client.User.Query().CountX(ctx))
+	err = client.WithTx(ctx, func(tx *ent.Tx) error {
+		return tx.Client().WithTx(ctx, func(*ent.Tx) error { return nil }, ent.TxBeginOptions(&sql.TxOptions{}))
+	})
+	require.Error(t, err)
+
+	// Other errors are not retried.
+	attempts = 0
+	err = client.WithTx(ctx, func(tx *ent.Tx) error {
+		attempts++
+		return tx.Post.Create().SetName("p").SetUserID(1000).Exec(ctx)
+	}, ent.TxRetry(3))
+	require.Error(t, err)
+	require.False(t, ent.IsRetryable(err))
+	require.Equal(t, 1, attempts)
+
+	// Errors are matched by their codes.
+	for err, retryable := range map[error]bool{
+		&pq.Error{Code: "40P01"}:                               true,
+		&pq.Error{Code: "23505"}:                               false,
+		&mysql.MySQLError{Number: 1213, Message: "Deadlock"}:   true,
+		fmt.Errorf("ent: %w", &mysql.MySQLError{Number: 1213}): true,
+		&mysql.MySQLError{Number: 1062, Message: "Duplicate"}:  false,
+		errors.New("Error 1213 (40001): Deadlock"):             false,
+		errors.New("database is locked"):                       false,
+		sqlite3.Error{Code: sqlite3.ErrLocked}:                 true,
+		sqlite3.Error{Code: sqlite3.ErrConstraint}:             false,
+	} {
+		require.Equal(t, retryable, ent.IsRetryable(err), err.Error())
+	}
+
+	// Errors of other drivers are classified by TxRetryIf.
+	attempts = 0
+	errConflict := errors.New("conflict")
+	err = client.WithTx(ctx, func(tx *ent.Tx) error {
+		if attempts++; attempts == 1 {
+			return errConflict
+		}
+		return nil
+	}, ent.TxRetry(3), ent.TxRetryIf(func(err error) bool {
+		return errors.Is(err, errConflict)
+	}), ent.TxBackoff(func(int) time.Duration { return time.Millisecond }))
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+}
+
+func TestNestedTx(t *testing.T) {
+	client := enttest.Open(t, dialect.SQLite, "file:nestedtx?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	tx, err := client.Tx(ctx)
+	require.NoError(t, err)
+	tx.User.Create().SetName("a").ExecX(ctx)
+
+	var hooks []string
+	inner, err := tx.Client().Tx(ctx)
+	require.NoError(t, err)
+	inner.OnRollback(func(next ent.Rollbacker) ent.Rollbacker {
+		return ent.RollbackFunc(func(ctx context.Context, tx *ent.Tx) error {
+			hooks = append(hooks, "rollback")
+			return next.Rollback(ctx, tx)
+		})
+	})
+	inner.User.Create().SetName("b").ExecX(ctx)
+	// Transactions can be nested at any depth.
+	innermost, err := inner.Client().Tx(ctx)
+	require.NoError(t, err)
+	innermost.User.Create().SetName("c").ExecX(ctx)
+	require.NoError(t, innermost.Commit())
+	require.Equal(t, 3, tx.User.Query().CountX(ctx))
+	require.NoError(t, inner.Rollback())
+	require.Equal(t, []string{"a"}, tx.User.Query().Select(user.FieldName).StringsX(ctx))
+
+	err = tx.Client().WithTx(ctx, func(tx *ent.Tx) error {
+		tx.OnCommit(func(next ent.Committer) ent.Committer {
+			return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+				hooks = append(hooks, "commit")
+				return next.Commit(ctx, tx)
+			})
+		})
+		return tx.User.Create().SetName("d").Exec(ctx)
+	})
+	require.NoError(t, err)
+	// Commit hooks of nested transactions are called when the outermost transaction is committed.
+	require.Equal(t, []string{"rollback"}, hooks)
+	_, err = tx.Client().BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	require.Error(t, err)
+	// Changes of nested transactions are committed with the enclosing transaction.
+	require.NoError(t, tx.Commit())
+	require.Equal(t, []string{"rollback", "commit"}, hooks)
+	require.Equal(t, []string{"a", "d"}, client.User.Query().Order(ent.Asc(user.FieldName)).Select(user.FieldName).StringsX(ctx))
+
+	// Committed nested transactions are rolled back with the outer transaction.
+	hooks = nil
+	tx, err = client.Tx(ctx)
+	require.NoError(t, err)
+	inner, err = tx.Client().Tx(ctx)
+	require.NoError(t, err)
+	inner.OnCommit(func(next ent.Committer) ent.Committer {
+		return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+			hooks = append(hooks, "commit")
+			return next.Commit(ctx, tx)
+		})
+	})
+	inner.OnRollback(func(next ent.Rollbacker) ent.Rollbacker {
+		return ent.RollbackFunc(func(ctx context.Context, tx *ent.Tx) error {
+			hooks = append(hooks, "rollback")
+			return next.Rollback(ctx, tx)
+		})
+	})
+	inner.User.Create().SetName("e").ExecX(ctx)
+	require.NoError(t, inner.Commit())
+	require.Empty(t, hooks)
+	require.NoError(t, tx.Rollback())
+	require.Equal(t, []string{"rollback"}, hooks)
+	require.Equal(t, 2, client.User.Query().CountX(ctx))
+
+	// Transactions can be nested in the transactions of the builders.
+	hooks = nil
+	client.Post.Use(func(next ent.Mutator) ent.Mutator {
+		return hook.PostFunc(func(ctx context.Context, m *ent.PostMutation) (ent.Value, error) {
+			if !m.Op().Is(ent.OpDelete) {
+				return next.Mutate(ctx, m)
+			}
+			tx, err := m.Client().Tx(ctx)
+			if err != nil {
+				return nil, err
+			}
+			tx.OnCommit(func(next ent.Committer) ent.Committer {
+				return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+					hooks = append(hooks, "commit")
+					return next.Commit(ctx, tx)
+				})
+			})
+			if err := tx.User.Create().SetName("f").Exec(ctx); err != nil {
+				return nil, err
+			}
+			if err := tx.Commit(); err != nil {
+				return nil, err
+			}
+			return next.Mutate(ctx, m)
+		})
+	})
+	d := client.User.Query().Where(user.Name("d")).OnlyX(ctx)
+	client.Post.Create().SetName("p").SetCreator(d).ExecX(ctx)
+	require.Equal(t, 1, client.User.Delete().Where(user.ID(d.ID)).Cascade().ExecX(ctx))
+	require.Equal(t, []string{"commit"}, hooks)
+	require.Equal(t, []string{"a", "f"}, client.User.Query().Order(ent.Asc(user.FieldName)).Select(user.FieldName).StringsX(ctx))
+}
+
+func TestTxContext(t *testing.T) {
+	client := enttest.Open(t, dialect.SQLite, "file:txcontext?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	require.Same(t, client, client.FromContext(ctx))
+	createUser := func(ctx context.Context, name string) error {
+		return client.FromContext(ctx).User.Create().SetName(name).Exec(ctx)
+	}
+	errFail := errors.New("fail")
+	err := client.WithTxContext(ctx, func(ctx context.Context) error {
+		require.NotNil(t, ent.TxFromContext(ctx))
+		if err := createUser(ctx, "a"); err != nil {
+			return err
+		}
+		return errFail
+	})
+	require.ErrorIs(t, err, errFail)
+	require.Zero(t, client.User.Query().CountX(ctx))
+
+	err = client.WithTxContext(ctx, func(ctx context.Context) error {
+		if err := createUser(ctx, "a"); err != nil {
+			return err
+		}
+		// Transactions that are started from the context are nested in its transaction.
+		err := client.WithTxContext(ctx, func(ctx context.Context) error {
+			if err := createUser(ctx, "b"); err != nil {
+				return err
+			}
+			return errFail
+		})
+		require.ErrorIs(t, err, errFail)
+		return client.WithTx(ctx, func(tx *ent.Tx) error {
+			return tx.User.Create().SetName("c").Exec(ctx)
+		})
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "c"}, client.User.Query().Order(ent.Asc(user.FieldName)).Select(user.FieldName).StringsX(ctx))
+}
+
+func TestReadReplicas(t *testing.T) {
+	dir := t.TempDir()
+	primary, replica := "file:"+dir+"/primary.db?_fk=1", "file:"+dir+"/replica.db?_fk=1"
+	enttest.Open(t, dialect.SQLite, replica).Close()
+	enttest.Open(t, dialect.SQLite, primary).Close()
+	drv, err := sql.Open(dialect.SQLite, replica)
+	require.NoError(t, err)
+	client, err := ent.Open(dialect.SQLite, primary, ent.ReadReplicas(drv))
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	a := client.User.Create().SetName("a").SaveX(ctx)
+	client.User.CreateBulk(client.User.Create().SetName("b")).ExecX(ctx)
+	// Reads are served by the replica, which was not updated.
+	require.Zero(t, client.User.Query().CountX(ctx))
+	require.Equal(t, 2, client.User.Query().CountX(ent.NewPrimaryContext(ctx)))
+	client.User.UpdateOne(a).SetName("c").ExecX(ctx)
+	require.Equal(t, "c", client.User.GetX(ent.NewPrimaryContext(ctx), a.ID).Name)
+
+	err = client.WithTx(ctx, func(tx *ent.Tx) error {
+		n, err := tx.User.Query().Count(ctx)
+		require.Equal(t, 2, n)
+		return err
+	})
+	require.NoError(t, err)
+
+	// Locking reads are executed on the primary. SQLite does not support them, so the
+	// statements are built for MySQL, and the queries that reach the replica are recorded.
+	db, err := stdsql.Open(dialect.SQLite, replica)
+	require.NoError(t, err)
+	var reads []string
+	mysql := ent.NewClient(
+		ent.Driver(sql.OpenDB(dialect.MySQL, db)),
+		ent.ReadReplicas(dialect.DebugWithContext(sql.OpenDB(dialect.MySQL, db), func(_ context.Context, v ...any) {
+			reads = append(reads, fmt.Sprint(v...))
+		})),
+	)
+	defer mysql.Close()
+	mysql.User.Query().AllX(ctx)
+	require.Len(t, reads, 1)
+	_, _ = mysql.User.Query().ForUpdate().All(ctx)
+	_, _ = mysql.User.Query().ForShare().Count(ctx)
+	_, _ = mysql.User.Query().Modify(func(s *sql.Selector) { s.For(sql.LockNoKeyUpdate) }).All(ctx)
+	require.Len(t, reads, 1)
+}
+
+func TestPool(t *testing.T) {
+	dir := t.TempDir()
+	enttest.Open(t, dialect.SQLite, "file:"+dir+"/replica.db?_fk=1").Close()
+	replica, err := sql.Open(dialect.SQLite, "file:"+dir+"/replica.db?_fk=1")
+	require.NoError(t, err)
+	client, err := ent.Open(dialect.SQLite, "file:"+dir+"/primary.db?_fk=1",
+		ent.MaxOpenConns(3),
+		ent.MaxIdleConns(2),
+		ent.ConnMaxLifetime(time.Minute),
+		ent.ReadReplicas(replica),
+		ent.Debug(),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, client.Ping(ctx))
+	require.NoError(t, client.Schema.Create(ctx))
+	stats := client.Stats()
+	require.Equal(t, 3, stats.MaxOpenConnections)
+	require.Positive(t, stats.OpenConnections)
+	tx, err := client.Tx(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, client.Stats().InUse)
+	require.NoError(t, tx.Client().Ping(ctx))
+	require.NoError(t, tx.Rollback())
+
+	require.NoError(t, replica.Close())
+	require.Error(t, client.Ping(ctx))
+	require.NoError(t, client.Close())
+	require.Error(t, client.Ping(ctx))
+}
+
+func TestQueryLog(t *testing.T) {
+	var entries []ent.QueryLogEntry
+	client := enttest.Open(t, dialect.SQLite, "file:querylog?mode=memory&cache=shared&_fk=1",
+		enttest.WithOptions(ent.QueryLogger(func(_ context.Context, e ent.QueryLogEntry) {
+			entries = append(entries, e)
+		}, ent.QueryLogRedact("users.name"), ent.QueryLogRedactFunc("posts.name", func(v any) any {
+			return fmt.Sprintf("%T", v)
+		}))),
+	)
+	defer client.Close()
+	ctx := context.Background()
+
+	entries = nil
+	a := client.User.Create().SetName("secret").SaveX(ctx)
+	require.Len(t, entries, 1)
+	require.Equal(t, dialect.SQLite, entries[0].Dialect)
+	require.False(t, entries[0].Tx)
+	require.NotContains(t, entries[0].Args, "secret")
+	require.Contains(t, entries[0].Args, "<redacted>")
+
+	// All arguments of the statements that reference a table with redacted columns are masked.
+	entries = nil
+	client.User.Query().Where(user.Name("secret"), user.IDIn(a.ID)).OnlyX(ctx)
+	require.Len(t, entries, 1)
+	require.Equal(t, []any{"<redacted>", "<redacted>"}, entries[0].Args)
+	require.EqualValues(t, -1, entries[0].RowsAffected)
+
+	entries = nil
+	client.User.Update().Where(user.ID(a.ID)).SetName("other").ExecX(ctx)
+	require.Len(t, entries, 1)
+	require.EqualValues(t, 1, entries[0].RowsAffected)
+	require.Equal(t, []any{"<redacted>", "<redacted>"}, entries[0].Args)
+
+	entries = nil
+	err := client.WithTx(ctx, func(tx *ent.Tx) error {
+		return tx.Post.Create().SetName("hello").SetCreatorID(a.ID).Exec(ctx)
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.True(t, entries[0].Tx)
+	require.Equal(t, []any{"string", "int"}, entries[0].Args)
+
+	entries = nil
+	client.Post.Create().SetName("hi").SetCreatorID(a.ID).
+		OnConflictColumns(post.FieldID).
+		Update(func(u *ent.PostUpsert) { u.SetName("hello") }).
+		ExecX(ctx)
+	require.Len(t, entries, 1)
+	require.Equal(t, []any{"string", "int", "string"}, entries[0].Args)
+
+	// Placeholders that are not bound to a redacted column, like function arguments, are masked.
+	entries = nil
+	client.User.Query().Where(func(s *sql.Selector) {
+		s.Where(sql.ExprP(s.C(user.FieldID)+" = lower(?)", "secret"))
+	}).ExistX(ctx)
+	require.Len(t, entries, 1)
+	require.Equal(t, []any{"<redacted>"}, entries[0].Args)
+
+	// Statements that reference more than one redacted column are masked.
+	entries = nil
+	client.User.Query().Where(user.HasPostsWith(post.Name("hello"))).ExistX(ctx)
+	require.Len(t, entries, 1)
+	require.Equal(t, []any{"<redacted>"}, entries[0].Args)
+
+	db, err := stdsql.Open(dialect.SQLite, "file:querylog?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+	pg := ent.NewClient(
+		ent.Driver(sql.OpenDB(dialect.Postgres, db)),
+		ent.QueryLogger(func(_ context.Context, e ent.QueryLogEntry) {
+			entries = append(entries, e)
+		}, ent.QueryLogRedact("users.name")),
+	)
+	defer pg.Close()
+	entries = nil
+	_ = pg.User.Update().Where(user.ID(a.ID)).SetName("secret").Exec(ctx)
+	require.NotEmpty(t, entries)
+	require.Equal(t, []any{"<redacted>", "<redacted>"}, entries[0].Args)
+	entries = nil
+	_ = pg.Post.Create().SetName("hello").SetCreatorID(a.ID).Exec(ctx)
+	require.NotEmpty(t, entries)
+	require.Equal(t, []any{"hello", a.ID}, entries[0].Args)
+
+	// Redacted columns must exist in the schema.
+	require.Panics(t, func() { ent.QueryLogRedact("users.password") })
+	require.Panics(t, func() { ent.QueryLogRedactFunc("name", func(v any) any { return v }) })
+
+	slow, err := ent.Open(dialect.SQLite, "file:querylog?mode=memory&cache=shared&_fk=1",
+		ent.QueryLogger(func(_ context.Context, e ent.QueryLogEntry) {
+			entries = append(entries, e)
+		}, ent.QueryLogSlow(time.Hour)),
+	)
+	require.NoError(t, err)
+	defer slow.Close()
+	entries = nil
+	require.Equal(t, 1, slow.User.Query().CountX(ctx))
+	require.Empty(t, entries)
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := ent.NewMetrics()
+	client := enttest.Open(t, dialect.SQLite, "file:metrics?mode=memory&cache=shared&_fk=1",
+		enttest.WithOptions(ent.CollectMetrics(metrics)),
+	)
+	defer client.Close()
+	ctx := context.Background()
+
+	a := client.User.Create().SetName("a").SaveX(ctx)
+	client.Post.Create().SetName("a-0").SetCreator(a).ExecX(ctx)
+	client.Post.Create().SetName("a-1").SetCreator(a).ExecX(ctx)
+	users := client.User.Query().WithPosts().AllX(ctx)
+	require.Len(t, users[0].Edges.Posts, 2)
+	require.True(t, client.User.Query().ExistX(ctx))
+	require.NoError(t, client.WithTx(ctx, func(tx *ent.Tx) error {
+		return tx.User.UpdateOne(a).SetName("b").Exec(ctx)
+	}))
+	require.Error(t, client.WithTx(ctx, func(tx *ent.Tx) error {
+		return errors.New("failed")
+	}))
+	// A deferred rollback of a committed transaction is not recorded.
+	require.NoError(t, func() error {
+		tx, err := client.Tx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		return tx.Commit()
+	}())
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE ent_statements_total counter",
+		`ent_statements_total{entity="User",operation="All",status="ok"} 1`,
+		`ent_statements_total{entity="Post",operation="Load",status="ok"} 1`,
+		`ent_statements_total{entity="User",operation="Exist",status="ok"} 1`,
+		`ent_statement_duration_seconds_count{entity="User",operation="All"} 1`,
+		`ent_statement_duration_seconds_bucket{entity="User",operation="All",le="+Inf"} 1`,
+		`ent_mutations_total{entity="Post",operation="Create",status="ok"} 2`,
+		`ent_mutations_total{entity="User",operation="UpdateOne",status="ok"} 1`,
+		// Including the transaction of the migration.
+		`ent_transactions_total{operation="Commit",status="ok"} 3`,
+		`ent_transactions_total{operation="Rollback",status="ok"} 1`,
+	} {
+		require.Contains(t, body, line+"\n")
+	}
+	require.NotContains(t, body, "SELECT")
+	require.NotContains(t, body, `ent_transactions_total{operation="Rollback",status="error"}`)
+}
+
+func TestTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	client := enttest.Open(t, dialect.SQLite, "file:trace?mode=memory&cache=shared&_fk=1",
+		enttest.WithOptions(ent.Trace(provider)),
+	)
+	defer client.Close()
+	ctx := context.Background()
+	attr := func(s tracetest.SpanStub, key string) string {
+		for _, kv := range s.Attributes {
+			if string(kv.Key) == key {
+				return kv.Value.AsString()
+			}
+		}
+		return ""
+	}
+	find := func(name string, attrs ...string) []tracetest.SpanStub {
+		var spans []tracetest.SpanStub
+	Spans:
+		for _, s := range exporter.GetSpans() {
+			for i := 0; i < len(attrs); i += 2 {
+				if attr(s, attrs[i]) != attrs[i+1] {
+					continue Spans
+				}
+			}
+			if s.Name == name {
+				spans = append(spans, s)
+			}
+		}
+		return spans
+	}
+
+	exporter.Reset()
+	a := client.User.Create().SetName("a").SaveX(ctx)
+	b := client.User.Create().SetName("b").SaveX(ctx)
+	mutations := find("ent.User.Create")
+	require.Len(t, mutations, 2)
+	statements := find("ent.Query", "ent.entity", "User", "ent.operation", "Create")
+	require.Len(t, statements, 2)
+	require.Equal(t, mutations[0].SpanContext.SpanID(), statements[0].Parent.SpanID())
+	require.Equal(t, mutations[0].SpanContext.TraceID(), statements[0].SpanContext.TraceID())
+	require.Equal(t, dialect.SQLite, attr(statements[0], "ent.dialect"))
+	require.Equal(t, "INSERT INTO `users` (`name`) VALUES (?) RETURNING `id`", attr(statements[0], "ent.fingerprint"))
+
+	exporter.Reset()
+	client.Post.Create().SetName("a-0").SetCreator(a).ExecX(ctx)
+	client.Post.Create().SetName("b-0").SetCreator(b).ExecX(ctx)
+	users := client.User.Query().WithPosts().AllX(ctx)
+	require.Len(t, users, 2)
+	require.Len(t, find("ent.Query", "ent.entity", "User", "ent.operation", "All"), 1)
+	loads := find("ent.Query", "ent.entity", "Post", "ent.operation", "Load")
+	require.Len(t, loads, 1)
+	require.False(t, loads[0].Parent.IsValid())
+	require.Contains(t, attr(loads[0], "ent.fingerprint"), "IN (?)")
+
+	exporter.Reset()
+	err := client.WithTx(ctx, func(tx *ent.Tx) error {
+		return tx.User.UpdateOne(a).SetName("c").Exec(ctx)
+	})
+	require.NoError(t, err)
+	txs := find("ent.Tx", "ent.operation", "Commit")
+	require.Len(t, txs, 1)
+	mutations = find("ent.User.UpdateOne")
+	require.Len(t, mutations, 1)
+	require.Equal(t, txs[0].SpanContext.SpanID(), mutations[0].Parent.SpanID())
+	statements = find("ent.Exec", "ent.operation", "UpdateOne")
+	require.NotEmpty(t, statements)
+	for _, s := range statements {
+		require.Equal(t, mutations[0].SpanContext.SpanID(), s.Parent.SpanID())
+		require.Equal(t, txs[0].SpanContext.TraceID(), s.SpanContext.TraceID())
+	}
+
+	// The span of a transaction is exported once, even if it is rolled back after its commit.
+	exporter.Reset()
+	tx, err := client.Tx(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	require.Error(t, tx.Rollback())
+	require.Len(t, find("ent.Tx"), 1)
+	require.Len(t, find("ent.Tx", "ent.operation", "Commit"), 1)
+
+	exporter.Reset()
+	ctx, root := provider.Tracer("test").Start(ctx, "request")
+	client.User.Query().CountX(ctx)
+	root.End()
+	spans := find("ent.Query", "ent.operation", "Count")
+	require.Len(t, spans, 1)
+	require.Equal(t, root.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	require.Equal(t, root.SpanContext().TraceID(), spans[0].SpanContext.TraceID())
+}
+
+func TestTimeout(t *testing.T) {
+	const dsn = "file:timeout?mode=memory&cache=shared&_fk=1"
+	client := enttest.Open(t, dialect.SQLite, dsn)
+	defer client.Close()
+	ctx := context.Background()
+	client.User.Create().SetName("a").ExecX(ctx)
+	slow := predicate.User(func(s *sql.Selector) {
+		s.Where(sql.ExprP("(WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM c WHERE x < 100000000) SELECT COUNT(*) FROM c) > 0"))
+	})
+
+	_, err := client.User.Query().Where(slow).Timeout(50 * time.Millisecond).All(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Queries with a timeout do not get the default timeout of the client.
+	timeouts, err := ent.Open(dialect.SQLite, dsn, ent.StatementTimeout(time.Nanosecond))
+	require.NoError(t, err)
+	defer timeouts.Close()
+	_, err = timeouts.User.Query().Count(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 1, timeouts.User.Query().Timeout(time.Minute).CountX(ctx))
+	_, err = timeouts.User.Query().Where(slow).Timeout(50 * time.Millisecond).IDs(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The timeout applies to all the ways of executing the query.
+	var groups []struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	err = client.User.Query().Where(slow).Timeout(50*time.Millisecond).GroupBy(user.FieldName).Aggregate(ent.Count()).Scan(ctx, &groups)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	err = client.User.Query().Where(slow).Timeout(50*time.Millisecond).Each(ctx, func(*ent.User) error { return nil })
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	err = client.User.Query().Where(slow).Timeout(50*time.Millisecond).ForEachBatch(ctx, 10, func([]*ent.User) error { return nil })
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = ent.Project[string](client.User.Query().Where(slow).Timeout(50*time.Millisecond),
+		ent.Field(ent.UserColumns.Name, func(s *string) *string { return s }),
+	).All(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, []string{"a"}, ent.Project[string](timeouts.User.Query().Timeout(time.Minute),
+		ent.Field(ent.UserColumns.Name, func(s *string) *string { return s }),
+	).AllX(ctx))
+
+	// SELECT statements on MySQL get a MAX_EXECUTION_TIME hint.
+	db, err := stdsql.Open(dialect.SQLite, dsn)
+	require.NoError(t, err)
+	var queries []string
+	mysql := ent.NewClient(
+		ent.Driver(sql.OpenDB(dialect.MySQL, db)),
+		ent.QueryLogger(func(_ context.Context, e ent.QueryLogEntry) {
+			queries = append(queries, e.Statement)
+		}),
+		ent.StatementTimeout(time.Minute),
+	)
+	defer mysql.Close()
+	require.Equal(t, 1, mysql.User.Query().CountX(ctx))
+	require.Len(t, queries, 1)
+	require.Regexp(t, `^SELECT /\*\+ MAX_EXECUTION_TIME\((59\d{3}|60000)\) \*/ COUNT\(`, queries[0])
+}
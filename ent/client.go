@@ -4,9 +4,12 @@ package ent
 
 import (
 	"context"
+	stdsql "database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"math/rand"
+	"time"
 
 	"entgo.io/bug/ent/migrate"
 
@@ -62,28 +65,29 @@ func Open(driverName, dataSourceName string, options ...Option) (*Client, error)
 
 // Tx returns a new transactional client. The provided context
 // is used until the transaction is committed or rolled back.
+// If the client is transactional, the returned Tx is nested in its
+// transaction using a savepoint, that is released on commit and
+// rolled back to on rollback.
 func (c *Client) Tx(ctx context.Context) (*Tx, error) {
 	if _, ok := c.driver.(*txDriver); ok {
-		return nil, errors.New("ent: cannot start a transaction within a transaction")
+		return c.nestedTx(ctx)
 	}
-	tx, err := newTx(ctx, c.driver)
+	tx, err := newTx(ctx, c.config)
 	if err != nil {
 		return nil, fmt.Errorf("ent: starting a transaction: %w", err)
 	}
-	cfg := c.config
-	cfg.driver = tx
-	return &Tx{
-		ctx:    ctx,
-		config: cfg,
-		Post:   NewPostClient(cfg),
-		User:   NewUserClient(cfg),
-	}, nil
+	return tx.owner, nil
 }
 
 // BeginTx returns a transactional client with specified options.
+// If the client is transactional, options are not supported, and
+// the returned Tx is nested in its transaction (see Client.Tx).
 func (c *Client) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
 	if _, ok := c.driver.(*txDriver); ok {
-		return nil, errors.New("ent: cannot start a transaction within a transaction")
+		if opts != nil {
+			return nil, errors.New("ent: cannot start a transaction with options within a transaction")
+		}
+		return c.nestedTx(ctx)
 	}
 	tx, err := c.driver.(interface {
 		BeginTx(context.Context, *sql.TxOptions) (dialect.Tx, error)
@@ -91,14 +95,16 @@ func (c *Client) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error)
 	if err != nil {
 		return nil, fmt.Errorf("ent: starting a transaction: %w", err)
 	}
+	drv := &txDriver{tx: tx, drv: c.driver}
 	cfg := c.config
-	cfg.driver = &txDriver{tx: tx, drv: c.driver}
-	return &Tx{
+	cfg.driver = drv
+	drv.owner = &Tx{
 		ctx:    ctx,
 		config: cfg,
 		Post:   NewPostClient(cfg),
 		User:   NewUserClient(cfg),
-	}, nil
+	}
+	return drv.owner, nil
 }
 
 // Debug returns a new debug-client. It's used to get verbose logging on specific operations.
@@ -131,6 +137,229 @@ func (c *Client) Use(hooks ...Hook) {
 	c.User.Use(hooks...)
 }
 
+// Stats returns the statistics of the connection pool of the (primary) database.
+// The zero value is returned for drivers that are not backed by a sql.DB.
+func (c *Client) Stats() stdsql.DBStats {
+	if db := dbOf(c.driver); db != nil {
+		return db.Stats()
+	}
+	return stdsql.DBStats{}
+}
+
+// Ping verifies that the database, and its read replicas (see ReadReplicas), are
+// reachable, and establishes connections to them if necessary.
+func (c *Client) Ping(ctx context.Context) error {
+	return ping(ctx, c.driver)
+}
+
+// ping pings the databases of the given driver, and of the drivers it wraps.
+func ping(ctx context.Context, drv dialect.Driver) error {
+	switch d := drv.(type) {
+	case interface{ DB() *stdsql.DB }:
+		return d.DB().PingContext(ctx)
+	case *txDriver:
+		return ping(ctx, d.drv)
+	case *dialect.DebugDriver:
+		return ping(ctx, d.Driver)
+	case *logDriver:
+		return ping(ctx, d.Driver)
+	case *metricsDriver:
+		return ping(ctx, d.Driver)
+	case *traceDriver:
+		return ping(ctx, d.Driver)
+	case *timeoutDriver:
+		return ping(ctx, d.Driver)
+	case *replicaDriver:
+		for _, drv := range append([]dialect.Driver{d.Driver}, d.replicas...) {
+			if err := ping(ctx, drv); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("ent: driver %T does not support Ping", drv)
+	}
+}
+
+// dbOf returns the sql.DB of the given driver, and of the drivers it wraps, or nil if
+// there isn't one.
+func dbOf(drv dialect.Driver) *stdsql.DB {
+	for {
+		switch d := drv.(type) {
+		case interface{ DB() *stdsql.DB }:
+			return d.DB()
+		case *txDriver:
+			drv = d.drv
+		case *replicaDriver:
+			drv = d.Driver
+		case *dialect.DebugDriver:
+			drv = d.Driver
+		case *logDriver:
+			drv = d.Driver
+		case *metricsDriver:
+			drv = d.Driver
+		case *traceDriver:
+			drv = d.Driver
+		case *timeoutDriver:
+			drv = d.Driver
+		default:
+			return nil
+		}
+	}
+}
+
+// TxOption configures the transactions of WithTx.
+type TxOption func(*txConfig)
+
+// txConfig holds the configuration of WithTx.
+type txConfig struct {
+	opts      *sql.TxOptions
+	attempts  int
+	backoff   func(int) time.Duration
+	retryable func(error) bool
+}
+
+// TxBeginOptions sets the options (e.g. the isolation level) that are used to begin the
+// transactions of WithTx. See Client.BeginTx.
+func TxBeginOptions(opts *sql.TxOptions) TxOption {
+	return func(c *txConfig) {
+		c.opts = opts
+	}
+}
+
+// TxRetry sets the maximum number of attempts of WithTx. Transactions that fail with a
+// retryable error (see IsRetryable and TxRetryIf) are executed again until they succeed,
+// or until the attempts are exhausted. Defaults to 1 (no retries).
+func TxRetry(attempts int) TxOption {
+	return func(c *txConfig) {
+		c.attempts = attempts
+	}
+}
+
+// TxBackoff sets the function that returns the delay before the given retry (starting
+// at 1) of WithTx. Defaults to an exponential backoff from 10ms up to 1s, with jitter.
+func TxBackoff(backoff func(retry int) time.Duration) TxOption {
+	return func(c *txConfig) {
+		c.backoff = backoff
+	}
+}
+
+// TxRetryIf sets the function that reports if an error of a transaction is retryable.
+// Defaults to IsRetryable.
+func TxRetryIf(retryable func(error) bool) TxOption {
+	return func(c *txConfig) {
+		c.retryable = retryable
+	}
+}
+
+// defaultTxBackoff is the default backoff of WithTx.
+func defaultTxBackoff(retry int) time.Duration {
+	d := time.Second
+	if retry < 8 {
+		d = 10 * time.Millisecond << (retry - 1)
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)))
+}
+
+// WithTx executes fn in a transaction, which is committed if fn returns nil, and rolled
+// back if it returns an error or panics. Panics are propagated after the rollback. With
+// TxRetry, fn may be executed more than once, and should not have side effects outside
+// of the transaction.
+//
+// If the client is transactional, or the context carries a transaction (see NewTxContext),
+// the transaction of fn is nested in it. Nested transactions are not retried, and their
+// errors are returned to the outermost WithTx, that retries the whole transaction. They
+// do not support TxBeginOptions.
+//
+//	err := client.WithTx(ctx, func(tx *ent.Tx) error {
+//		u, err := tx.User.Create().SetName("a8m").Save(ctx)
+//		if err != nil {
+//			return err
+//		}
+//		return tx.Post.Create().SetName("hello").SetCreator(u).Exec(ctx)
+//	})
+//
+func (c *Client) WithTx(ctx context.Context, fn func(tx *Tx) error, opts ...TxOption) error {
+	cfg := txConfig{attempts: 1, backoff: defaultTxBackoff, retryable: IsRetryable}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	client := c.FromContext(ctx)
+	if _, ok := client.driver.(*txDriver); ok {
+		if cfg.opts != nil {
+			return errors.New("ent: cannot start a transaction with options within a transaction")
+		}
+		cfg.attempts = 1
+	}
+	for retry := 1; ; retry++ {
+		err := client.withTx(ctx, cfg, fn)
+		if err == nil || retry >= cfg.attempts || !cfg.retryable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: retrying transaction: %v", err, ctx.Err())
+		case <-time.After(cfg.backoff(retry)):
+		}
+	}
+}
+
+// WithTxContext is like WithTx, but fn gets a context that carries the transaction,
+// instead of the transaction itself. Functions that are called with this context, and
+// get their client using Client.FromContext, join the transaction.
+//
+//	err := client.WithTxContext(ctx, func(ctx context.Context) error {
+//		return createUser(ctx, client, "a8m")
+//	})
+//
+//	func createUser(ctx context.Context, client *ent.Client, name string) error {
+//		return client.FromContext(ctx).User.Create().SetName(name).Exec(ctx)
+//	}
+//
+func (c *Client) WithTxContext(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	return c.WithTx(ctx, func(tx *Tx) error {
+		return fn(NewTxContext(ctx, tx))
+	}, opts...)
+}
+
+// FromContext returns the client of the transaction that is carried by the context (see
+// NewTxContext), or c if there isn't one.
+func (c *Client) FromContext(ctx context.Context) *Client {
+	if tx := TxFromContext(ctx); tx != nil {
+		return tx.Client()
+	}
+	return c
+}
+
+// withTx executes fn in one transaction that is started using the given config.
+func (c *Client) withTx(ctx context.Context, cfg txConfig, fn func(*Tx) error) error {
+	var (
+		tx  *Tx
+		err error
+	)
+	if cfg.opts != nil {
+		tx, err = c.BeginTx(ctx, cfg.opts)
+	} else {
+		tx, err = c.Tx(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			_ = tx.Rollback()
+			panic(v)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			err = fmt.Errorf("%w: rolling back transaction: %v", err, rerr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
 // PostClient is a client for the Post schema.
 type PostClient struct {
 	config
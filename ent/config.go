@@ -3,8 +3,13 @@
 package ent
 
 import (
+	"context"
+	stdsql "database/sql"
+	"time"
+
 	"entgo.io/ent"
 	"entgo.io/ent/dialect"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Option function to configure the client.
@@ -20,6 +25,8 @@ type config struct {
 	log func(...any)
 	// hooks to execute on mutations.
 	hooks *hooks
+	// wrappers of the driver, applied by their order.
+	wrappers []func(dialect.Driver) dialect.Driver
 }
 
 // hooks per client, for fast access.
@@ -33,6 +40,9 @@ func (c *config) options(opts ...Option) {
 	for _, opt := range opts {
 		opt(c)
 	}
+	for _, wrap := range c.wrappers {
+		c.driver = wrap(c.driver)
+	}
 	if c.debug {
 		c.driver = dialect.Debug(c.driver, c.log)
 	}
@@ -58,3 +68,133 @@ func Driver(driver dialect.Driver) Option {
 		c.driver = driver
 	}
 }
+
+// CollectMetrics configures the client to record the metrics of its statements, mutations
+// and transactions in m. See Metrics.
+func CollectMetrics(m *Metrics) Option {
+	return func(c *config) {
+		c.wrappers = append(c.wrappers, func(drv dialect.Driver) dialect.Driver {
+			return &metricsDriver{Driver: drv, metrics: m}
+		})
+		c.hooks.Post = append(c.hooks.Post, m.hook)
+		c.hooks.User = append(c.hooks.User, m.hook)
+	}
+}
+
+// MaxOpenConns sets the maximum number of open connections to the database.
+// See sql.DB.SetMaxOpenConns.
+func MaxOpenConns(n int) Option {
+	return pool(func(db *stdsql.DB) { db.SetMaxOpenConns(n) })
+}
+
+// MaxIdleConns sets the maximum number of idle connections in the pool.
+// See sql.DB.SetMaxIdleConns.
+func MaxIdleConns(n int) Option {
+	return pool(func(db *stdsql.DB) { db.SetMaxIdleConns(n) })
+}
+
+// ConnMaxLifetime sets the maximum amount of time a connection may be reused.
+// See sql.DB.SetConnMaxLifetime.
+func ConnMaxLifetime(d time.Duration) Option {
+	return pool(func(db *stdsql.DB) { db.SetConnMaxLifetime(d) })
+}
+
+// ConnMaxIdleTime sets the maximum amount of time a connection may be idle.
+// See sql.DB.SetConnMaxIdleTime.
+func ConnMaxIdleTime(d time.Duration) Option {
+	return pool(func(db *stdsql.DB) { db.SetConnMaxIdleTime(d) })
+}
+
+// pool returns an option that configures the database of the primary driver of the
+// client using fn. It has no effect on drivers that are not backed by a sql.DB.
+func pool(fn func(*stdsql.DB)) Option {
+	return func(c *config) {
+		c.wrappers = append(c.wrappers, func(drv dialect.Driver) dialect.Driver {
+			if db := dbOf(drv); db != nil {
+				fn(db)
+			}
+			return drv
+		})
+	}
+}
+
+// QueryLogger configures the client to report each statement it executes, including the
+// statements of transactions, to fn. See QueryLogEntry and QueryLogOption.
+//
+//	client, err := ent.Open(dialect.Postgres, dsn,
+//		ent.QueryLogger(func(ctx context.Context, e ent.QueryLogEntry) {
+//			log.Printf("%s %v (%s)", e.Statement, e.Args, e.Duration)
+//		}, ent.QueryLogRedact("users.name"), ent.QueryLogSlow(100*time.Millisecond)),
+//	)
+//
+func QueryLogger(fn func(context.Context, QueryLogEntry), opts ...QueryLogOption) Option {
+	l := &queryLogger{fn: fn, redact: make(map[string]func(any) any)}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return func(c *config) {
+		c.wrappers = append(c.wrappers, func(drv dialect.Driver) dialect.Driver {
+			return &logDriver{Driver: drv, logger: l}
+		})
+	}
+}
+
+// ReadReplicas configures the client to execute the SELECT queries that run outside of
+// transactions on the given replicas, chosen in round-robin. Other statements, locking
+// reads (e.g. queries with ForUpdate or ForShare) and all transactions, are executed by
+// the primary driver of the client (see Driver). Queries can be forced to the primary,
+// e.g. to read the writes of a request, using NewPrimaryContext.
+func ReadReplicas(replicas ...dialect.Driver) Option {
+	return func(c *config) {
+		// The replicas are routed first, so other wrappers (e.g. QueryLogger) apply to them too.
+		c.wrappers = append([]func(dialect.Driver) dialect.Driver{func(primary dialect.Driver) dialect.Driver {
+			return &replicaDriver{Driver: primary, replicas: replicas}
+		}}, c.wrappers...)
+	}
+}
+
+// StatementTimeout sets the default timeout of the statements of the client. It applies to the
+// statements of queries without a timeout of their own (see the Timeout method of the queries),
+// and of mutations. The timeouts are enforced by the deadlines of the contexts of the statements,
+// and where supported, also by the database: SELECT statements on MySQL get a MAX_EXECUTION_TIME
+// hint with the time remaining until their deadline, and transactions on PostgreSQL set their
+// statement_timeout to the default timeout. A zero timeout sets no default, but still enables
+// the enforcement of the deadlines of statements by the database.
+//
+// Note that on PostgreSQL, the statement_timeout is set only inside transactions (using SET
+// LOCAL, which does not outlive them), as the statements outside of them may run on different
+// connections of the pool. The statements outside of transactions are bounded only by the
+// deadlines of their contexts, which cancel them on the database when they expire.
+func StatementTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.wrappers = append(c.wrappers, func(drv dialect.Driver) dialect.Driver {
+			return &timeoutDriver{Driver: drv, timeout: timeout}
+		})
+	}
+}
+
+// Trace configures the client to trace its statements, mutations and transactions, using
+// OpenTelemetry spans that are created by the given provider. The spans of the client are:
+//
+//	ent.Exec, ent.Query
+//		The statements executed by the driver. Their attributes are the entity and the
+//		operation that executed them (e.g. "User" and "All", or "Post" and "Load" for
+//		eager-loading), the dialect, and the fingerprint of the statement.
+//	ent.<Entity>.<Op>, e.g. ent.Post.Create
+//		The mutations, which are the parents of their statements.
+//	ent.Tx
+//		The transactions, from their beginning until their commit or rollback, which are
+//		the parents of their mutations and statements.
+//
+// The spans of the client are nested in the span that is carried by the context, if any
+// (see trace.ContextWithSpan).
+func Trace(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		t := &tracer{Tracer: provider.Tracer("entgo.io/bug/ent")}
+		c.wrappers = append(c.wrappers, func(drv dialect.Driver) dialect.Driver {
+			return &traceDriver{Driver: drv, tracer: t}
+		})
+		c.hooks.Post = append(c.hooks.Post, t.hook)
+		c.hooks.User = append(c.hooks.User, t.hook)
+	}
+}
@@ -3,8 +3,10 @@
 package ent
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"entgo.io/bug/ent/post"
 	"entgo.io/bug/ent/user"
@@ -16,6 +18,8 @@ type Post struct {
 	config `json:"-"`
 	// ID of the ent.
 	ID int `json:"id,omitempty"`
+	// DeletedAt holds the value of the "deleted_at" field.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 	// Name holds the value of the "name" field.
 	Name string `json:"name,omitempty"`
 	// UserID holds the value of the "user_id" field.
@@ -23,6 +27,8 @@ type Post struct {
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the PostQuery when eager-loading is set.
 	Edges PostEdges `json:"edges"`
+
+	selectValues map[string]any
 }
 
 // PostEdges holds the relations/edges for other nodes in the graph.
@@ -56,8 +62,10 @@ func (*Post) scanValues(columns []string) ([]any, error) {
 			values[i] = new(sql.NullInt64)
 		case post.FieldName:
 			values[i] = new(sql.NullString)
+		case post.FieldDeletedAt:
+			values[i] = new(sql.NullTime)
 		default:
-			return nil, fmt.Errorf("unexpected column %q for type Post", columns[i])
+			values[i] = new(any)
 		}
 	}
 	return values, nil
@@ -77,6 +85,13 @@ func (po *Post) assignValues(columns []string, values []any) error {
 				return fmt.Errorf("unexpected type %T for field id", value)
 			}
 			po.ID = int(value.Int64)
+		case post.FieldDeletedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field deleted_at", values[i])
+			} else if value.Valid {
+				po.DeletedAt = new(time.Time)
+				*po.DeletedAt = value.Time
+			}
 		case post.FieldName:
 			if value, ok := values[i].(*sql.NullString); !ok {
 				return fmt.Errorf("unexpected type %T for field name", values[i])
@@ -89,6 +104,11 @@ func (po *Post) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				po.UserID = int(value.Int64)
 			}
+		default:
+			if po.selectValues == nil {
+				po.selectValues = make(map[string]any)
+			}
+			po.selectValues[columns[i]] = *values[i].(*any)
 		}
 	}
 	return nil
@@ -122,6 +142,11 @@ func (po *Post) String() string {
 	var builder strings.Builder
 	builder.WriteString("Post(")
 	builder.WriteString(fmt.Sprintf("id=%v, ", po.ID))
+	if v := po.DeletedAt; v != nil {
+		builder.WriteString("deleted_at=")
+		builder.WriteString(v.Format(time.ANSIC))
+	}
+	builder.WriteString(", ")
 	builder.WriteString("name=")
 	builder.WriteString(po.Name)
 	builder.WriteString(", ")
@@ -131,6 +156,33 @@ func (po *Post) String() string {
 	return builder.String()
 }
 
+// LoadCreator loads the "creator" edge of the Post. If the context carries a Loader
+// (see NewLoaderContext), the load is batched with the concurrent loads of other Posts.
+func (po *Post) LoadCreator(ctx context.Context) (*User, error) {
+	l := LoaderFromContext(ctx)
+	if l == nil {
+		return po.QueryCreator().Only(ctx)
+	}
+	switch v, err := l.postCreator.load(ctx, po.ID); {
+	case err != nil:
+		return nil, err
+	case v == nil:
+		return nil, &NotFoundError{user.Label}
+	default:
+		return v, nil
+	}
+}
+
+// Value returns the value of a column that was selected dynamically (e.g. using Modify), and
+// is not a field of the Post.
+func (po *Post) Value(name string) (Value, error) {
+	v, ok := po.selectValues[name]
+	if !ok {
+		return nil, fmt.Errorf("ent: value %q was not selected for type Post", name)
+	}
+	return v, nil
+}
+
 // Posts is a parsable slice of Post.
 type Posts []*Post
 
@@ -7,6 +7,8 @@ const (
 	Label = "post"
 	// FieldID holds the string denoting the id field in the database.
 	FieldID = "id"
+	// FieldDeletedAt holds the string denoting the deleted_at field in the database.
+	FieldDeletedAt = "deleted_at"
 	// FieldName holds the string denoting the name field in the database.
 	FieldName = "name"
 	// FieldUserID holds the string denoting the user_id field in the database.
@@ -27,6 +29,7 @@ const (
 // Columns holds all SQL columns for post fields.
 var Columns = []string{
 	FieldID,
+	FieldDeletedAt,
 	FieldName,
 	FieldUserID,
 }
@@ -40,3 +43,7 @@ func ValidColumn(column string) bool {
 	}
 	return false
 }
+
+// CreatorDeletedAtColumn is the soft-deletion column of the User entity of the "creator" edge.
+// It exists in this package in order to avoid circular dependency with the "user" package.
+const CreatorDeletedAtColumn = "deleted_at"
@@ -3,6 +3,8 @@
 package post
 
 import (
+	"time"
+
 	"entgo.io/bug/ent/predicate"
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
@@ -36,7 +38,7 @@ func IDIn(ids ...int) predicate.Post {
 		for i := range v {
 			v[i] = ids[i]
 		}
-		s.Where(sql.In(s.C(FieldID), v...))
+		s.Where(predicate.In(s.C(FieldID), false, v...))
 	})
 }
 
@@ -47,7 +49,7 @@ func IDNotIn(ids ...int) predicate.Post {
 		for i := range v {
 			v[i] = ids[i]
 		}
-		s.Where(sql.NotIn(s.C(FieldID), v...))
+		s.Where(predicate.In(s.C(FieldID), true, v...))
 	})
 }
 
@@ -79,6 +81,13 @@ func IDLTE(id int) predicate.Post {
 	})
 }
 
+// DeletedAt applies equality check predicate on the "deleted_at" field. It's identical to DeletedAtEQ.
+func DeletedAt(v time.Time) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(sql.EQ(s.C(FieldDeletedAt), v))
+	})
+}
+
 // Name applies equality check predicate on the "name" field. It's identical to NameEQ.
 func Name(v string) predicate.Post {
 	return predicate.Post(func(s *sql.Selector) {
@@ -93,6 +102,84 @@ func UserID(v int) predicate.Post {
 	})
 }
 
+// DeletedAtEQ applies the EQ predicate on the "deleted_at" field.
+func DeletedAtEQ(v time.Time) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(sql.EQ(s.C(FieldDeletedAt), v))
+	})
+}
+
+// DeletedAtNEQ applies the NEQ predicate on the "deleted_at" field.
+func DeletedAtNEQ(v time.Time) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(sql.NEQ(s.C(FieldDeletedAt), v))
+	})
+}
+
+// DeletedAtIn applies the In predicate on the "deleted_at" field.
+func DeletedAtIn(vs ...time.Time) predicate.Post {
+	v := make([]any, len(vs))
+	for i := range v {
+		v[i] = vs[i]
+	}
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(predicate.In(s.C(FieldDeletedAt), false, v...))
+	})
+}
+
+// DeletedAtNotIn applies the NotIn predicate on the "deleted_at" field.
+func DeletedAtNotIn(vs ...time.Time) predicate.Post {
+	v := make([]any, len(vs))
+	for i := range v {
+		v[i] = vs[i]
+	}
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(predicate.In(s.C(FieldDeletedAt), true, v...))
+	})
+}
+
+// DeletedAtGT applies the GT predicate on the "deleted_at" field.
+func DeletedAtGT(v time.Time) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(sql.GT(s.C(FieldDeletedAt), v))
+	})
+}
+
+// DeletedAtGTE applies the GTE predicate on the "deleted_at" field.
+func DeletedAtGTE(v time.Time) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(sql.GTE(s.C(FieldDeletedAt), v))
+	})
+}
+
+// DeletedAtLT applies the LT predicate on the "deleted_at" field.
+func DeletedAtLT(v time.Time) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(sql.LT(s.C(FieldDeletedAt), v))
+	})
+}
+
+// DeletedAtLTE applies the LTE predicate on the "deleted_at" field.
+func DeletedAtLTE(v time.Time) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(sql.LTE(s.C(FieldDeletedAt), v))
+	})
+}
+
+// DeletedAtIsNil applies the IsNil predicate on the "deleted_at" field.
+func DeletedAtIsNil() predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(sql.IsNull(s.C(FieldDeletedAt)))
+	})
+}
+
+// DeletedAtNotNil applies the NotNil predicate on the "deleted_at" field.
+func DeletedAtNotNil() predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(sql.NotNull(s.C(FieldDeletedAt)))
+	})
+}
+
 // NameEQ applies the EQ predicate on the "name" field.
 func NameEQ(v string) predicate.Post {
 	return predicate.Post(func(s *sql.Selector) {
@@ -114,7 +201,7 @@ func NameIn(vs ...string) predicate.Post {
 		v[i] = vs[i]
 	}
 	return predicate.Post(func(s *sql.Selector) {
-		s.Where(sql.In(s.C(FieldName), v...))
+		s.Where(predicate.In(s.C(FieldName), false, v...))
 	})
 }
 
@@ -125,7 +212,7 @@ func NameNotIn(vs ...string) predicate.Post {
 		v[i] = vs[i]
 	}
 	return predicate.Post(func(s *sql.Selector) {
-		s.Where(sql.NotIn(s.C(FieldName), v...))
+		s.Where(predicate.In(s.C(FieldName), true, v...))
 	})
 }
 
@@ -213,7 +300,7 @@ func UserIDIn(vs ...int) predicate.Post {
 		v[i] = vs[i]
 	}
 	return predicate.Post(func(s *sql.Selector) {
-		s.Where(sql.In(s.C(FieldUserID), v...))
+		s.Where(predicate.In(s.C(FieldUserID), false, v...))
 	})
 }
 
@@ -224,20 +311,13 @@ func UserIDNotIn(vs ...int) predicate.Post {
 		v[i] = vs[i]
 	}
 	return predicate.Post(func(s *sql.Selector) {
-		s.Where(sql.NotIn(s.C(FieldUserID), v...))
+		s.Where(predicate.In(s.C(FieldUserID), true, v...))
 	})
 }
 
 // HasCreator applies the HasEdge predicate on the "creator" edge.
 func HasCreator() predicate.Post {
-	return predicate.Post(func(s *sql.Selector) {
-		step := sqlgraph.NewStep(
-			sqlgraph.From(Table, FieldID),
-			sqlgraph.To(CreatorTable, FieldID),
-			sqlgraph.Edge(sqlgraph.M2O, true, CreatorTable, CreatorColumn),
-		)
-		sqlgraph.HasNeighbors(s, step)
-	})
+	return predicate.Post(HasCreatorWith())
 }
 
 // HasCreatorWith applies the HasEdge predicate on the "creator" edge with a given conditions (other predicates).
@@ -249,6 +329,7 @@ func HasCreatorWith(preds ...predicate.User) predicate.Post {
 			sqlgraph.Edge(sqlgraph.M2O, true, CreatorTable, CreatorColumn),
 		)
 		sqlgraph.HasNeighborsWith(s, step, func(s *sql.Selector) {
+			s.Where(sql.IsNull(s.C(CreatorDeletedAtColumn)))
 			for _, p := range preds {
 				p(s)
 			}
@@ -287,3 +368,67 @@ func Not(p predicate.Post) predicate.Post {
 		p(s.Not())
 	})
 }
+
+// IDInQuery applies the In predicate on the ID field, with the IDs that are selected
+// by the subquery (e.g. PostQuery.SelectIDs).
+func IDInQuery(q predicate.Subquery[int]) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldID), false, q))
+	})
+}
+
+// IDNotInQuery applies the NotIn predicate on the ID field, with the IDs that are selected
+// by the subquery (e.g. PostQuery.SelectIDs).
+func IDNotInQuery(q predicate.Subquery[int]) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldID), true, q))
+	})
+}
+
+// DeletedAtInQuery applies the In predicate on the "deleted_at" field, with the values that
+// are selected by the subquery.
+func DeletedAtInQuery(q predicate.Subquery[time.Time]) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldDeletedAt), false, q))
+	})
+}
+
+// DeletedAtNotInQuery applies the NotIn predicate on the "deleted_at" field, with the values that
+// are selected by the subquery.
+func DeletedAtNotInQuery(q predicate.Subquery[time.Time]) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldDeletedAt), true, q))
+	})
+}
+
+// NameInQuery applies the In predicate on the "name" field, with the values that
+// are selected by the subquery.
+func NameInQuery(q predicate.Subquery[string]) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldName), false, q))
+	})
+}
+
+// NameNotInQuery applies the NotIn predicate on the "name" field, with the values that
+// are selected by the subquery.
+func NameNotInQuery(q predicate.Subquery[string]) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldName), true, q))
+	})
+}
+
+// UserIDInQuery applies the In predicate on the "user_id" field, with the values that
+// are selected by the subquery.
+func UserIDInQuery(q predicate.Subquery[int]) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldUserID), false, q))
+	})
+}
+
+// UserIDNotInQuery applies the NotIn predicate on the "user_id" field, with the values that
+// are selected by the subquery.
+func UserIDNotInQuery(q predicate.Subquery[int]) predicate.Post {
+	return predicate.Post(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldUserID), true, q))
+	})
+}
@@ -5,6 +5,7 @@ package ent
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"entgo.io/bug/ent/post"
 	"entgo.io/bug/ent/predicate"
@@ -68,23 +69,29 @@ func (pd *PostDelete) ExecX(ctx context.Context) int {
 }
 
 func (pd *PostDelete) sqlExec(ctx context.Context) (int, error) {
-	_spec := &sqlgraph.DeleteSpec{
+	_spec := &sqlgraph.UpdateSpec{
 		Node: &sqlgraph.NodeSpec{
-			Table: post.Table,
+			Table:   post.Table,
+			Columns: post.Columns,
 			ID: &sqlgraph.FieldSpec{
 				Type:   field.TypeInt,
 				Column: post.FieldID,
 			},
 		},
 	}
-	if ps := pd.mutation.predicates; len(ps) > 0 {
-		_spec.Predicate = func(selector *sql.Selector) {
-			for i := range ps {
-				ps[i](selector)
-			}
+	_spec.Fields.Set = append(_spec.Fields.Set, &sqlgraph.FieldSpec{
+		Type:   field.TypeTime,
+		Value:  time.Now(),
+		Column: post.FieldDeletedAt,
+	})
+	ps := pd.mutation.predicates
+	_spec.Predicate = func(selector *sql.Selector) {
+		selector.Where(sql.IsNull(selector.C(post.FieldDeletedAt)))
+		for i := range ps {
+			ps[i](selector)
 		}
 	}
-	affected, err := sqlgraph.DeleteNodes(ctx, pd.driver, _spec)
+	affected, err := sqlgraph.UpdateNodes(ctx, pd.driver, _spec)
 	if err != nil && sqlgraph.IsConstraintError(err) {
 		err = &ConstraintError{msg: err.Error(), wrap: err}
 	}
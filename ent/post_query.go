@@ -4,12 +4,16 @@ package ent
 
 import (
 	"context"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"math"
+	"time"
 
 	"entgo.io/bug/ent/post"
 	"entgo.io/bug/ent/predicate"
 	"entgo.io/bug/ent/user"
+	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/schema/field"
@@ -25,6 +29,14 @@ type PostQuery struct {
 	fields      []string
 	predicates  []predicate.Post
 	withCreator *UserQuery
+	eagerJoin   bool
+	locked      bool
+	// lock sets the locking clause of the query on a selector, that locks the rows of
+	// the given tables, or of all tables if none are given.
+	lock        func(*sql.Selector, ...string)
+	modifiers   []func(*sql.Selector)
+	withDeleted bool
+	timeout     time.Duration
 	// intermediate query (i.e. traversal path).
 	sql  *sql.Selector
 	path func(context.Context) (*sql.Selector, error)
@@ -258,18 +270,15 @@ func (pq *PostQuery) Clone() *PostQuery {
 	if pq == nil {
 		return nil
 	}
-	return &PostQuery{
-		config:      pq.config,
-		limit:       pq.limit,
-		offset:      pq.offset,
-		order:       append([]OrderFunc{}, pq.order...),
-		predicates:  append([]predicate.Post{}, pq.predicates...),
-		withCreator: pq.withCreator.Clone(),
-		// clone intermediate query.
-		sql:    pq.sql.Clone(),
-		path:   pq.path,
-		unique: pq.unique,
-	}
+	clone := *pq
+	clone.order = append([]OrderFunc{}, pq.order...)
+	clone.fields = append([]string{}, pq.fields...)
+	clone.predicates = append([]predicate.Post{}, pq.predicates...)
+	clone.withCreator = pq.withCreator.Clone()
+	clone.modifiers = append([]func(*sql.Selector){}, pq.modifiers...)
+	// clone intermediate query.
+	clone.sql = pq.sql.Clone()
+	return &clone
 }
 
 // WithCreator tells the query-builder to eager-load the nodes that are connected to
@@ -289,17 +298,17 @@ func (pq *PostQuery) WithCreator(opts ...func(*UserQuery)) *PostQuery {
 // Example:
 //
 //	var v []struct {
-//		Name string `json:"name,omitempty"`
+//		DeletedAt time.Time `json:"deleted_at,omitempty"`
 //		Count int `json:"count,omitempty"`
 //	}
 //
 //	client.Post.Query().
-//		GroupBy(post.FieldName).
+//		GroupBy(post.FieldDeletedAt).
 //		Aggregate(ent.Count()).
 //		Scan(ctx, &v)
 //
 func (pq *PostQuery) GroupBy(field string, fields ...string) *PostGroupBy {
-	grbuild := &PostGroupBy{config: pq.config}
+	grbuild := &PostGroupBy{config: pq.config, timeout: pq.timeout}
 	grbuild.fields = append([]string{field}, fields...)
 	grbuild.path = func(ctx context.Context) (prev *sql.Selector, err error) {
 		if err := pq.prepareQuery(ctx); err != nil {
@@ -318,11 +327,11 @@ func (pq *PostQuery) GroupBy(field string, fields ...string) *PostGroupBy {
 // Example:
 //
 //	var v []struct {
-//		Name string `json:"name,omitempty"`
+//		DeletedAt time.Time `json:"deleted_at,omitempty"`
 //	}
 //
 //	client.Post.Query().
-//		Select(post.FieldName).
+//		Select(post.FieldDeletedAt).
 //		Scan(ctx, &v)
 //
 func (pq *PostQuery) Select(fields ...string) *PostSelect {
@@ -339,6 +348,9 @@ func (pq *PostQuery) prepareQuery(ctx context.Context) error {
 			return &ValidationError{Name: f, err: fmt.Errorf("ent: invalid field %q for query", f)}
 		}
 	}
+	if pq.locked && pq.driver.Dialect() == dialect.SQLite {
+		return errors.New("ent: row-level locking is not supported by sqlite")
+	}
 	if pq.path != nil {
 		prev, err := pq.path(ctx)
 		if err != nil {
@@ -350,6 +362,9 @@ func (pq *PostQuery) prepareQuery(ctx context.Context) error {
 }
 
 func (pq *PostQuery) sqlAll(ctx context.Context, hooks ...queryHook) ([]*Post, error) {
+	ctx = newOperationContext(ctx, "Post", "All")
+	ctx, cancel := newTimeoutContext(ctx, pq.timeout)
+	defer cancel()
 	var (
 		nodes       = []*Post{}
 		_spec       = pq.querySpec()
@@ -366,6 +381,24 @@ func (pq *PostQuery) sqlAll(ctx context.Context, hooks ...queryHook) ([]*Post, e
 		node.Edges.loadedTypes = loadedTypes
 		return node.assignValues(columns, values)
 	}
+	if len(pq.modifiers) > 0 {
+		_spec.Modifiers = pq.modifiers
+	}
+	if !pq.withDeleted {
+		pred := _spec.Predicate
+		_spec.Predicate = func(selector *sql.Selector) {
+			selector.Where(sql.IsNull(selector.C(post.FieldDeletedAt)))
+			if pred != nil {
+				pred(selector)
+			}
+		}
+	}
+	joinCreator := pq.eagerJoin && pq.withCreator != nil && pq.withCreator.joinable()
+	if joinCreator {
+		if err := pq.joinCreator(ctx, _spec, func() *Post { return nodes[len(nodes)-1] }); err != nil {
+			return nil, err
+		}
+	}
 	for i := range hooks {
 		hooks[i](ctx, _spec)
 	}
@@ -375,7 +408,7 @@ func (pq *PostQuery) sqlAll(ctx context.Context, hooks ...queryHook) ([]*Post, e
 	if len(nodes) == 0 {
 		return nodes, nil
 	}
-	if query := pq.withCreator; query != nil {
+	if query := pq.withCreator; query != nil && !joinCreator {
 		if err := pq.loadCreator(ctx, query, nodes, nil,
 			func(n *Post, e *User) { n.Edges.Creator = e }); err != nil {
 			return nil, err
@@ -385,6 +418,7 @@ func (pq *PostQuery) sqlAll(ctx context.Context, hooks ...queryHook) ([]*Post, e
 }
 
 func (pq *PostQuery) loadCreator(ctx context.Context, query *UserQuery, nodes []*Post, init func(*Post), assign func(*Post, *User)) error {
+	ctx = newLoadContext(ctx, "User")
 	ids := make([]int, 0, len(nodes))
 	nodeids := make(map[int][]*Post)
 	for i := range nodes {
@@ -394,25 +428,46 @@ func (pq *PostQuery) loadCreator(ctx context.Context, query *UserQuery, nodes []
 		}
 		nodeids[fk] = append(nodeids[fk], nodes[i])
 	}
-	query.Where(user.IDIn(ids...))
-	neighbors, err := query.All(ctx)
-	if err != nil {
-		return err
-	}
-	for _, n := range neighbors {
-		nodes, ok := nodeids[n.ID]
-		if !ok {
-			return fmt.Errorf(`unexpected foreign-key "user_id" returned %v`, n.ID)
+	preds := query.predicates
+	for _, ids := range chunkIn(query.driver.Dialect(), ids) {
+		query.predicates = append(preds[:len(preds):len(preds)], user.IDIn(ids...))
+		neighbors, err := query.All(ctx)
+		if err != nil {
+			return err
 		}
-		for i := range nodes {
-			assign(nodes[i], n)
+		for _, n := range neighbors {
+			nodes, ok := nodeids[n.ID]
+			if !ok {
+				return fmt.Errorf(`unexpected foreign-key "user_id" returned %v`, n.ID)
+			}
+			for i := range nodes {
+				assign(nodes[i], n)
+			}
 		}
 	}
 	return nil
 }
 
 func (pq *PostQuery) sqlCount(ctx context.Context) (int, error) {
+	ctx = newOperationContext(ctx, "Post", "Count")
+	ctx, cancel := newTimeoutContext(ctx, pq.timeout)
+	defer cancel()
+	if pq.locked {
+		return pq.sqlLockedCount(ctx)
+	}
 	_spec := pq.querySpec()
+	if len(pq.modifiers) > 0 {
+		_spec.Modifiers = pq.modifiers
+	}
+	if !pq.withDeleted {
+		pred := _spec.Predicate
+		_spec.Predicate = func(selector *sql.Selector) {
+			selector.Where(sql.IsNull(selector.C(post.FieldDeletedAt)))
+			if pred != nil {
+				pred(selector)
+			}
+		}
+	}
 	_spec.Node.Columns = pq.fields
 	if len(pq.fields) > 0 {
 		_spec.Unique = pq.unique != nil && *pq.unique
@@ -421,6 +476,7 @@ func (pq *PostQuery) sqlCount(ctx context.Context) (int, error) {
 }
 
 func (pq *PostQuery) sqlExist(ctx context.Context) (bool, error) {
+	ctx = newOperationContext(ctx, "Post", "Exist")
 	switch _, err := pq.FirstID(ctx); {
 	case IsNotFound(err):
 		return false, nil
@@ -494,6 +550,13 @@ func (pq *PostQuery) sqlQuery(ctx context.Context) *sql.Selector {
 	if pq.unique != nil && *pq.unique {
 		selector.Distinct()
 	}
+	selector.WithContext(ctx)
+	for _, m := range pq.modifiers {
+		m(selector)
+	}
+	if !pq.withDeleted {
+		selector.Where(sql.IsNull(selector.C(post.FieldDeletedAt)))
+	}
 	for _, p := range pq.predicates {
 		p(selector)
 	}
@@ -511,12 +574,474 @@ func (pq *PostQuery) sqlQuery(ctx context.Context) *sql.Selector {
 	return selector
 }
 
+// ForEachBatch executes the query in pages of the given size, ordered by the Post ID,
+// and calls fn with each page. It stops on the first error returned by fn. Order, Limit and
+// Offset are not supported. The timeout of the query applies to the loading of each page.
+func (pq *PostQuery) ForEachBatch(ctx context.Context, size int, fn func([]*Post) error) error {
+	return pq.forEachBatch(ctx, size, func(page func(config) ([]*Post, error)) ([]*Post, error) {
+		nodes, err := page(pq.config)
+		if err == nil && len(nodes) > 0 {
+			err = fn(nodes)
+		}
+		return nodes, err
+	})
+}
+
+// ForEachBatchTx is like ForEachBatch, but each page is loaded and processed in its own
+// transaction, which is committed if fn succeeds and rolled back otherwise. The Posts,
+// including the edges that are eager-loaded with them, are loaded in the transaction and bound to
+// it, and should not be used after fn returns.
+func (pq *PostQuery) ForEachBatchTx(ctx context.Context, size int, fn func(*Tx, []*Post) error) error {
+	client := &Client{config: pq.config}
+	return pq.forEachBatch(ctx, size, func(page func(config) ([]*Post, error)) ([]*Post, error) {
+		tx, err := client.Tx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		nodes, err := page(tx.config)
+		if err == nil && len(nodes) > 0 {
+			err = fn(tx, nodes)
+		}
+		if err != nil {
+			if rerr := tx.Rollback(); rerr != nil {
+				err = fmt.Errorf("%w: rolling back transaction: %v", err, rerr)
+			}
+			return nil, err
+		}
+		return nodes, tx.Commit()
+	})
+}
+
+// forEachBatch pages over the results of the query. batch is called for each page with
+// a function that loads it using the given config, and returns the loaded Posts.
+// The pages are loaded by a clone of the query, which is left unchanged.
+func (pq *PostQuery) forEachBatch(ctx context.Context, size int, batch func(func(config) ([]*Post, error)) ([]*Post, error)) error {
+	switch {
+	case size <= 0:
+		return fmt.Errorf("ent: invalid batch size %d", size)
+	case len(pq.order) > 0 || pq.limit != nil || pq.offset != nil:
+		return errors.New("ent: PostQuery.ForEachBatch does not support Order, Limit and Offset")
+	}
+	ctx = newOperationContext(ctx, "Post", "ForEachBatch")
+	preds := pq.predicates
+	var last *int
+	for {
+		nodes, err := batch(func(cfg config) ([]*Post, error) {
+			query := pq.Clone()
+			query.setConfig(cfg)
+			query.predicates = preds[:len(preds):len(preds)]
+			if last != nil {
+				query.predicates = append(query.predicates, post.IDGT(*last))
+			}
+			return query.Order(Asc(post.FieldID)).Limit(size).All(ctx)
+		})
+		if err != nil || len(nodes) < size {
+			return err
+		}
+		last = &nodes[len(nodes)-1].ID
+	}
+}
+
+// setConfig sets the config of the query, and of the queries of the edges it eager-loads.
+func (pq *PostQuery) setConfig(cfg config) {
+	pq.config = cfg
+	if pq.withCreator != nil {
+		pq.withCreator.setConfig(cfg)
+	}
+}
+
+// EagerJoin configures the query-builder to eager-load its unique edges using a LEFT JOIN,
+// instead of a second query. Edges that are eager-loaded with options that cannot be applied
+// to a joined table (field selection, pagination or eager-loading of their own edges), and
+// non-unique edges are loaded as usual. Note that joined neighbors are not shared between
+// the nodes that point to them.
+func (pq *PostQuery) EagerJoin() *PostQuery {
+	pq.eagerJoin = true
+	return pq
+}
+
+// joinable reports if the query can be used as a joined table for eager-loading.
+func (pq *PostQuery) joinable() bool {
+	return len(pq.fields) == 0 && pq.limit == nil && pq.offset == nil && pq.withCreator == nil
+}
+
+// joinCreator configures the spec to eager-load the "creator" edge using a LEFT JOIN.
+// last returns the node that was assigned last.
+func (pq *PostQuery) joinCreator(ctx context.Context, spec *sqlgraph.QuerySpec, last func() *Post) error {
+	query := pq.withCreator
+	if err := query.prepareQuery(ctx); err != nil {
+		return err
+	}
+	t := query.sqlQuery(ctx).As("t_creator")
+	spec.Modifiers = append(spec.Modifiers[:len(spec.Modifiers):len(spec.Modifiers)], func(s *sql.Selector) {
+		s.LeftJoin(t).On(s.C(post.CreatorColumn), t.C(user.FieldID))
+		for _, c := range user.Columns {
+			s.AppendSelect(sql.As(t.C(c), "creator__"+c))
+		}
+		// Lock only the rows of the query, as the joined table is on the nullable
+		// side of the join, which cannot be locked in PostgreSQL.
+		if pq.lock != nil {
+			pq.lock(s, s.TableName())
+		}
+	})
+	scan, assign := spec.ScanValues, spec.Assign
+	spec.ScanValues = func(columns []string) ([]any, error) {
+		i := len(columns) - len(user.Columns)
+		values, err := scan(columns[:i])
+		if err != nil {
+			return nil, err
+		}
+		neighbor, err := (*User).scanValues(nil, user.Columns)
+		if err != nil {
+			return nil, err
+		}
+		return append(values, neighbor...), nil
+	}
+	spec.Assign = func(columns []string, values []any) error {
+		i := len(columns) - len(user.Columns)
+		if err := assign(columns[:i], values[:i]); err != nil {
+			return err
+		}
+		// A NULL identifier means that the node has no neighbor.
+		if v, ok := values[i].(driver.Valuer); ok {
+			if id, err := v.Value(); err != nil || id == nil {
+				return err
+			}
+		}
+		neighbor := &User{config: query.config}
+		if err := neighbor.assignValues(user.Columns, values[i:]); err != nil {
+			return err
+		}
+		last().Edges.Creator = neighbor
+		return nil
+	}
+	return nil
+}
+
+// Iter executes the query and returns an iterator that scans the Posts one at a
+// time. The iterator holds a database connection until it is exhausted or closed, so it must
+// always be closed. The timeout of the query lasts until the iterator is closed. Eager-loading
+// of edges is not supported by iterators.
+func (pq *PostQuery) Iter(ctx context.Context) (_ *PostIterator, err error) {
+	if pq.withCreator != nil {
+		return nil, errors.New("ent: eager-loading creator is not supported by PostQuery.Iter")
+	}
+	ctx = newOperationContext(ctx, "Post", "Iter")
+	ctx, cancel := newTimeoutContext(ctx, pq.timeout)
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+	if err := pq.prepareQuery(ctx); err != nil {
+		return nil, err
+	}
+	selector := pq.sqlQuery(ctx)
+	if fields := pq.fields; len(fields) > 0 {
+		columns := []string{post.FieldID}
+		for i := range fields {
+			if fields[i] != post.FieldID {
+				columns = append(columns, fields[i])
+			}
+		}
+		selector.Select(selector.Columns(columns...)...)
+	}
+	if unique := pq.unique; unique == nil || *unique {
+		selector.Distinct()
+	}
+	if err := selector.Err(); err != nil {
+		return nil, err
+	}
+	rows := &sql.Rows{}
+	query, args := selector.Query()
+	if err := pq.driver.Query(ctx, query, args, rows); err != nil {
+		return nil, err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return &PostIterator{config: pq.config, rows: rows, columns: columns, cancel: cancel}, nil
+}
+
+// Each executes the query and calls fn for each of the Posts it returns,
+// while they are being scanned. It stops on the first error returned by fn.
+func (pq *PostQuery) Each(ctx context.Context, fn func(*Post) error) error {
+	it, err := pq.Iter(ctx)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for it.Next() {
+		if err := fn(it.Value()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// PostIterator iterates over the results of a PostQuery. See PostQuery.Iter for more info.
+type PostIterator struct {
+	config
+	rows    *sql.Rows
+	columns []string
+	node    *Post
+	err     error
+	// cancel cancels the timeout of the query.
+	cancel context.CancelFunc
+}
+
+// Next scans the next Post, and reports if there was one. It returns false
+// when the results are exhausted or an error occurred, and Err should be checked.
+func (it *PostIterator) Next() bool {
+	if it.err != nil || !it.rows.Next() {
+		return false
+	}
+	values, err := (*Post).scanValues(nil, it.columns)
+	if err == nil {
+		err = it.rows.Scan(values...)
+	}
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.node = &Post{config: it.config}
+	if it.err = it.node.assignValues(it.columns, values); it.err != nil {
+		return false
+	}
+	return true
+}
+
+// Value returns the Post that was scanned by the last call to Next.
+func (it *PostIterator) Value() *Post {
+	return it.node
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *PostIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.rows.Err()
+}
+
+// Close closes the iterator and releases its database connection.
+func (it *PostIterator) Close() error {
+	defer it.cancel()
+	return it.rows.Close()
+}
+
+// ForUpdate locks the selected rows against concurrent updates, and prevent them from being
+// updated, deleted or "selected ... for update" by other sessions, until the transaction is
+// either committed or rolled-back. The lock can be configured to fail or to skip locked rows,
+// instead of waiting for them, using sql.WithLockAction(sql.NoWait) or sql.WithLockAction(sql.SkipLocked)
+// (MySQL 8 and PostgreSQL only).
+//
+// If the query eager-loads its edges using a LEFT JOIN (see EagerJoin), only its own rows are
+// locked (FOR UPDATE OF). Row locks are not supported by SQLite, which locks the whole database
+// for writing, and the query fails with an error.
+func (pq *PostQuery) ForUpdate(opts ...sql.LockOption) *PostQuery {
+	if pq.driver.Dialect() == dialect.Postgres {
+		pq.Unique(false)
+	}
+	lock := func(s *sql.Selector, tables ...string) {
+		s.ForUpdate(append([]sql.LockOption{sql.WithLockTables(tables...)}, opts...)...)
+	}
+	pq.locked, pq.lock = true, lock
+	pq.modifiers = append(pq.modifiers, func(s *sql.Selector) {
+		lock(s)
+	})
+	return pq
+}
+
+// ForShare behaves similarly to ForUpdate, except that it acquires a shared mode lock
+// on any rows that are read. Other sessions can read the rows, but cannot modify them
+// until your transaction commits. In MySQL, a lock without options of a query that is
+// not joined is written as LOCK IN SHARE MODE, which is supported by all versions.
+func (pq *PostQuery) ForShare(opts ...sql.LockOption) *PostQuery {
+	if pq.driver.Dialect() == dialect.Postgres {
+		pq.Unique(false)
+	}
+	mysql := pq.driver.Dialect() == dialect.MySQL
+	lock := func(s *sql.Selector, tables ...string) {
+		if mysql && len(opts) == 0 && len(tables) == 0 {
+			s.ForShare(sql.WithLockClause("LOCK IN SHARE MODE"))
+			return
+		}
+		s.ForShare(append([]sql.LockOption{sql.WithLockTables(tables...)}, opts...)...)
+	}
+	pq.locked, pq.lock = true, lock
+	pq.modifiers = append(pq.modifiers, func(s *sql.Selector) {
+		lock(s)
+	})
+	return pq
+}
+
+// sqlLockedCount counts the rows of a query with a lock, by locking them in a subquery:
+// SELECT COUNT(*) FROM (SELECT ... FOR UPDATE).
+func (pq *PostQuery) sqlLockedCount(ctx context.Context) (int, error) {
+	selector := pq.sqlQuery(ctx)
+	if len(pq.fields) == 0 {
+		selector.Select(selector.C(post.FieldID))
+		if pq.unique == nil {
+			selector.Distinct()
+		}
+	}
+	query, args := sql.Dialect(pq.driver.Dialect()).
+		Select(sql.Count("*")).
+		From(selector.As("t")).
+		Query()
+	rows := &sql.Rows{}
+	if err := pq.driver.Query(ctx, query, args, rows); err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	return sql.ScanInt(rows)
+}
+
+// Modify adds a query modifier for attaching custom logic to queries.
+func (pq *PostQuery) Modify(modifiers ...func(s *sql.Selector)) *PostSelect {
+	pq.modifiers = append(pq.modifiers, modifiers...)
+	return pq.Select()
+}
+
+// project calls scan with the selector of a projection of the query, and its config, under
+// the timeout of the query. See Project.
+func (pq *PostQuery) project(ctx context.Context, scan func(context.Context, *sql.Selector, config) error) error {
+	ctx = newOperationContext(ctx, "Post", "Project")
+	ctx, cancel := newTimeoutContext(ctx, pq.timeout)
+	defer cancel()
+	if err := pq.prepareQuery(ctx); err != nil {
+		return err
+	}
+	return scan(ctx, pq.sqlQuery(ctx), pq.config)
+}
+
+// Union returns a query of the Posts that are returned by the query or by one of
+// the other queries. The queries should not be used after, and should not have Order, Limit
+// or Offset. The Timeout, the modifiers (e.g. Modify) and the eager-loading of the query apply
+// to the returned query, and the other queries should not have them. The returned query
+// supports Order, Limit, Offset and other options, like any other query.
+func (pq *PostQuery) Union(others ...*PostQuery) *PostQuery {
+	return pq.setOp(opUnion, others)
+}
+
+// Intersect returns a query of the Posts that are returned by the query and by all
+// of the other queries. See Union for more info.
+func (pq *PostQuery) Intersect(others ...*PostQuery) *PostQuery {
+	return pq.setOp(opIntersect, others)
+}
+
+// Except returns a query of the Posts that are returned by the query and by none
+// of the other queries. See Union for more info.
+func (pq *PostQuery) Except(others ...*PostQuery) *PostQuery {
+	return pq.setOp(opExcept, others)
+}
+
+// setOp returns a query of the Posts whose IDs are returned by the set operation on
+// the query and the other queries. The options of the query that apply to the returned nodes
+// are moved to the returned query.
+func (pq *PostQuery) setOp(op setOp, others []*PostQuery) *PostQuery {
+	query := &PostQuery{
+		config:      pq.config,
+		timeout:     pq.timeout,
+		modifiers:   pq.modifiers,
+		eagerJoin:   pq.eagerJoin,
+		withCreator: pq.withCreator,
+		locked:      pq.locked,
+		lock:        pq.lock,
+		withDeleted: true,
+	}
+	operand := &PostQuery{
+		config:      pq.config,
+		predicates:  pq.predicates,
+		order:       pq.order,
+		limit:       pq.limit,
+		offset:      pq.offset,
+		sql:         pq.sql,
+		path:        pq.path,
+		withDeleted: pq.withDeleted,
+	}
+	query.path = func(ctx context.Context) (*sql.Selector, error) {
+		selectors := make([]*sql.Selector, 0, len(others)+1)
+		for _, q := range append([]*PostQuery{operand}, others...) {
+			switch {
+			case len(q.order) > 0 || q.limit != nil || q.offset != nil:
+				return nil, fmt.Errorf("ent: operands of %s do not support Order, Limit and Offset", op)
+			case q.timeout != 0 || len(q.modifiers) > 0 || q.withCreator != nil:
+				return nil, fmt.Errorf("ent: other operands of %s do not support Timeout, modifiers and eager-loading", op)
+			}
+			selector, err := q.subquery(ctx, post.FieldID)
+			if err != nil {
+				return nil, err
+			}
+			selectors = append(selectors, selector)
+		}
+		builder := sql.Dialect(pq.driver.Dialect())
+		t1 := builder.Table(post.Table)
+		return builder.Select().From(t1).WithContext(ctx).Where(setIn(op, t1.C(post.FieldID), selectors)), nil
+	}
+	return query
+}
+
+// IncludeDeleted configures the query-builder to return soft-deleted Posts as well.
+// Note that it applies only to this query, and not to the edges it loads or traverses.
+func (pq *PostQuery) IncludeDeleted() *PostQuery {
+	pq.withDeleted = true
+	return pq
+}
+
+// SelectIDs returns a subquery that selects the IDs of the Posts of the query. It can
+// be used in the InQuery and NotInQuery predicates of other queries (e.g. IDInQuery), and the query
+// should not be used after. The subquery is built with the context of the statement of the
+// outer query, and therefore, shares its operation, timeout and tracing.
+func (pq *PostQuery) SelectIDs() predicate.Subquery[int] {
+	return predicate.NewSubquery[int](func(ctx context.Context) (*sql.Selector, error) {
+		return pq.subquery(ctx, post.FieldID)
+	})
+}
+
+// SelectUserIDs returns a subquery that selects the "user_id" field of the Posts of the
+// query, like SelectIDs.
+func (pq *PostQuery) SelectUserIDs() predicate.Subquery[int] {
+	return predicate.NewSubquery[int](func(ctx context.Context) (*sql.Selector, error) {
+		return pq.subquery(ctx, post.FieldUserID)
+	})
+}
+
+// subquery returns the selector of the query, that selects only the given column.
+func (pq *PostQuery) subquery(ctx context.Context, column string) (*sql.Selector, error) {
+	if err := pq.prepareQuery(ctx); err != nil {
+		return nil, err
+	}
+	selector := pq.sqlQuery(ctx)
+	selector.Select(selector.C(column))
+	return selector, nil
+}
+
+// Timeout sets the timeout of the query, including the eager-loading of its edges. It replaces
+// the default timeout of the client (see StatementTimeout), and applies to all the ways of
+// executing the query (e.g. Select, GroupBy, Iter and Project).
+//
+//	posts, err := client.Post.Query().
+//		Order(ent.Asc(post.FieldID)).
+//		Timeout(5 * time.Second).
+//		All(ctx)
+//
+func (pq *PostQuery) Timeout(d time.Duration) *PostQuery {
+	pq.timeout = d
+	return pq
+}
+
 // PostGroupBy is the group-by builder for Post entities.
 type PostGroupBy struct {
 	config
 	selector
 	fields []string
 	fns    []AggregateFunc
+	// timeout of the query, inherited from the query-builder.
+	timeout time.Duration
 	// intermediate query (i.e. traversal path).
 	sql  *sql.Selector
 	path func(context.Context) (*sql.Selector, error)
@@ -530,6 +1055,9 @@ func (pgb *PostGroupBy) Aggregate(fns ...AggregateFunc) *PostGroupBy {
 
 // Scan applies the group-by query and scans the result into the given value.
 func (pgb *PostGroupBy) Scan(ctx context.Context, v any) error {
+	ctx = newOperationContext(ctx, "Post", "GroupBy")
+	ctx, cancel := newTimeoutContext(ctx, pgb.timeout)
+	defer cancel()
 	query, err := pgb.path(ctx)
 	if err != nil {
 		return err
@@ -594,6 +1122,9 @@ func (ps *PostSelect) Scan(ctx context.Context, v any) error {
 }
 
 func (ps *PostSelect) sqlScan(ctx context.Context, v any) error {
+	ctx = newOperationContext(ctx, "Post", "Select")
+	ctx, cancel := newTimeoutContext(ctx, ps.timeout)
+	defer cancel()
 	rows := &sql.Rows{}
 	query, args := ps.sql.Query()
 	if err := ps.driver.Query(ctx, query, args, rows); err != nil {
@@ -602,3 +1133,9 @@ func (ps *PostSelect) sqlScan(ctx context.Context, v any) error {
 	defer rows.Close()
 	return sql.ScanSlice(rows, v)
 }
+
+// Modify adds a query modifier for attaching custom logic to queries.
+func (ps *PostSelect) Modify(modifiers ...func(s *sql.Selector)) *PostSelect {
+	ps.modifiers = append(ps.modifiers, modifiers...)
+	return ps
+}
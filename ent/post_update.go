@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"entgo.io/bug/ent/post"
 	"entgo.io/bug/ent/predicate"
@@ -28,6 +29,26 @@ func (pu *PostUpdate) Where(ps ...predicate.Post) *PostUpdate {
 	return pu
 }
 
+// SetDeletedAt sets the "deleted_at" field.
+func (pu *PostUpdate) SetDeletedAt(t time.Time) *PostUpdate {
+	pu.mutation.SetDeletedAt(t)
+	return pu
+}
+
+// SetNillableDeletedAt sets the "deleted_at" field if the given value is not nil.
+func (pu *PostUpdate) SetNillableDeletedAt(t *time.Time) *PostUpdate {
+	if t != nil {
+		pu.SetDeletedAt(*t)
+	}
+	return pu
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (pu *PostUpdate) ClearDeletedAt() *PostUpdate {
+	pu.mutation.ClearDeletedAt()
+	return pu
+}
+
 // SetName sets the "name" field.
 func (pu *PostUpdate) SetName(s string) *PostUpdate {
 	pu.mutation.SetName(s)
@@ -148,6 +169,19 @@ func (pu *PostUpdate) sqlSave(ctx context.Context) (n int, err error) {
 			}
 		}
 	}
+	if value, ok := pu.mutation.DeletedAt(); ok {
+		_spec.Fields.Set = append(_spec.Fields.Set, &sqlgraph.FieldSpec{
+			Type:   field.TypeTime,
+			Value:  value,
+			Column: post.FieldDeletedAt,
+		})
+	}
+	if pu.mutation.DeletedAtCleared() {
+		_spec.Fields.Clear = append(_spec.Fields.Clear, &sqlgraph.FieldSpec{
+			Type:   field.TypeTime,
+			Column: post.FieldDeletedAt,
+		})
+	}
 	if value, ok := pu.mutation.Name(); ok {
 		_spec.Fields.Set = append(_spec.Fields.Set, &sqlgraph.FieldSpec{
 			Type:   field.TypeString,
@@ -209,6 +243,26 @@ type PostUpdateOne struct {
 	mutation *PostMutation
 }
 
+// SetDeletedAt sets the "deleted_at" field.
+func (puo *PostUpdateOne) SetDeletedAt(t time.Time) *PostUpdateOne {
+	puo.mutation.SetDeletedAt(t)
+	return puo
+}
+
+// SetNillableDeletedAt sets the "deleted_at" field if the given value is not nil.
+func (puo *PostUpdateOne) SetNillableDeletedAt(t *time.Time) *PostUpdateOne {
+	if t != nil {
+		puo.SetDeletedAt(*t)
+	}
+	return puo
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (puo *PostUpdateOne) ClearDeletedAt() *PostUpdateOne {
+	puo.mutation.ClearDeletedAt()
+	return puo
+}
+
 // SetName sets the "name" field.
 func (puo *PostUpdateOne) SetName(s string) *PostUpdateOne {
 	puo.mutation.SetName(s)
@@ -359,6 +413,19 @@ func (puo *PostUpdateOne) sqlSave(ctx context.Context) (_node *Post, err error)
 			}
 		}
 	}
+	if value, ok := puo.mutation.DeletedAt(); ok {
+		_spec.Fields.Set = append(_spec.Fields.Set, &sqlgraph.FieldSpec{
+			Type:   field.TypeTime,
+			Value:  value,
+			Column: post.FieldDeletedAt,
+		})
+	}
+	if puo.mutation.DeletedAtCleared() {
+		_spec.Fields.Clear = append(_spec.Fields.Clear, &sqlgraph.FieldSpec{
+			Type:   field.TypeTime,
+			Column: post.FieldDeletedAt,
+		})
+	}
 	if value, ok := puo.mutation.Name(); ok {
 		_spec.Fields.Set = append(_spec.Fields.Set, &sqlgraph.FieldSpec{
 			Type:   field.TypeString,
@@ -11,6 +11,13 @@ type Post struct {
 	ent.Schema
 }
 
+// Mixin of the Post.
+func (Post) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		SoftDeleteMixin{},
+	}
+}
+
 // Fields of the Post.
 func (Post) Fields() []ent.Field {
 	return []ent.Field{
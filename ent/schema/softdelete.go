@@ -0,0 +1,41 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/mixin"
+)
+
+// SoftDelete is a schema annotation that marks an entity as soft-deletable.
+// Delete operations on annotated entities set the Field column instead of
+// removing the row, and queries skip rows where the column is set.
+type SoftDelete struct {
+	Field string `json:"field,omitempty"`
+}
+
+// Name implements the schema.Annotation interface.
+func (SoftDelete) Name() string {
+	return "SoftDelete"
+}
+
+// SoftDeleteMixin adds the "deleted_at" field and the SoftDelete annotation to a schema.
+type SoftDeleteMixin struct {
+	mixin.Schema
+}
+
+// Fields of the SoftDeleteMixin.
+func (SoftDeleteMixin) Fields() []ent.Field {
+	return []ent.Field{
+		field.Time("deleted_at").
+			Optional().
+			Nillable(),
+	}
+}
+
+// Annotations of the SoftDeleteMixin.
+func (SoftDeleteMixin) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		SoftDelete{Field: "deleted_at"},
+	}
+}
@@ -4,7 +4,9 @@ package ent
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"entgo.io/ent/dialect"
 )
@@ -67,6 +69,9 @@ func (f CommitFunc) Commit(ctx context.Context, tx *Tx) error {
 // Commit commits the transaction.
 func (tx *Tx) Commit() error {
 	txDriver := tx.config.driver.(*txDriver)
+	if sp, ok := txDriver.tx.(*savepoint); ok {
+		return sp.commit(tx)
+	}
 	var fn Committer = CommitFunc(func(context.Context, *Tx) error {
 		return txDriver.tx.Commit()
 	})
@@ -171,15 +176,46 @@ type txDriver struct {
 	drv dialect.Driver
 	// tx is the underlying transaction.
 	tx dialect.Tx
+	// owner is the Tx of the transaction, which is the parent of the Txs nested in it.
+	owner *Tx
 }
 
-// newTx creates a new transactional driver.
-func newTx(ctx context.Context, drv dialect.Driver) (*txDriver, error) {
-	tx, err := drv.Tx(ctx)
+// newTx creates a new transactional driver from the driver of the config, and the Tx that
+// owns it. If the driver is transactional, the new driver joins its transaction, and is owned
+// by its Tx.
+func newTx(ctx context.Context, cfg config) (*txDriver, error) {
+	tx, err := cfg.driver.Tx(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &txDriver{tx: tx, drv: drv}, nil
+	drv := &txDriver{tx: tx, drv: cfg.driver}
+	if parent, ok := cfg.driver.(*txDriver); ok {
+		drv.owner = parent.owner
+		return drv, nil
+	}
+	cfg.driver = drv
+	drv.owner = &Tx{ctx: ctx, config: cfg}
+	drv.owner.init()
+	return drv, nil
+}
+
+// commit commits a transaction that was started by the internal builders using newTx. The
+// transaction is committed by its owner, which calls the commit hooks of the Txs that were
+// nested in it. A transaction that joined the transaction of a Tx is left to the Tx.
+func (tx *txDriver) commit() error {
+	if _, ok := tx.tx.(*txDriver); ok {
+		return nil
+	}
+	return tx.owner.Commit()
+}
+
+// rollback rolls back a transaction that was started by the internal builders using newTx,
+// like commit.
+func (tx *txDriver) rollback() error {
+	if _, ok := tx.tx.(*txDriver); ok {
+		return nil
+	}
+	return tx.owner.Rollback()
 }
 
 // Tx returns the transaction wrapper (txDriver) to avoid Commit or Rollback calls
@@ -211,3 +247,68 @@ func (tx *txDriver) Query(ctx context.Context, query string, args, v any) error
 }
 
 var _ dialect.Driver = (*txDriver)(nil)
+
+// savepoints generates the names of the savepoints.
+var savepoints uint64
+
+// nestedTx returns a Tx that is nested in the transaction of the client using a savepoint.
+// Committing the nested Tx releases the savepoint, and its changes are committed with the
+// enclosing transaction. Rolling it back undoes only the changes made since the savepoint.
+//
+// The commit and rollback hooks of a committed nested Tx are added to its parent, as its
+// changes are committed or rolled back with the parent. The hooks are then called with the
+// parent Tx. The rollback hooks of a rolled back nested Tx are called on its rollback.
+func (c *Client) nestedTx(ctx context.Context) (*Tx, error) {
+	parent := c.driver.(*txDriver)
+	sp := &savepoint{Tx: parent.tx, parent: parent.owner, ctx: ctx, name: fmt.Sprintf("ent_%d", atomic.AddUint64(&savepoints, 1))}
+	if err := sp.exec("SAVEPOINT"); err != nil {
+		return nil, fmt.Errorf("ent: starting a nested transaction: %w", err)
+	}
+	drv := &txDriver{tx: sp, drv: parent.drv}
+	cfg := c.config
+	cfg.driver = drv
+	drv.owner = &Tx{ctx: ctx, config: cfg}
+	drv.owner.init()
+	return drv.owner, nil
+}
+
+// savepoint is a transaction that is nested in another transaction using a SAVEPOINT.
+type savepoint struct {
+	dialect.Tx
+	parent *Tx
+	ctx    context.Context
+	name   string
+}
+
+// Commit releases the savepoint.
+func (sp *savepoint) Commit() error {
+	return sp.exec("RELEASE SAVEPOINT")
+}
+
+// Rollback rolls back to the savepoint, and releases it.
+func (sp *savepoint) Rollback() error {
+	if err := sp.exec("ROLLBACK TO SAVEPOINT"); err != nil {
+		return err
+	}
+	return sp.exec("RELEASE SAVEPOINT")
+}
+
+// commit releases the savepoint of the given nested Tx, and adds its hooks to the parent Tx.
+func (sp *savepoint) commit(tx *Tx) error {
+	if err := sp.Commit(); err != nil {
+		return err
+	}
+	tx.mu.Lock()
+	onCommit, onRollback := tx.onCommit, tx.onRollback
+	tx.mu.Unlock()
+	sp.parent.mu.Lock()
+	defer sp.parent.mu.Unlock()
+	sp.parent.onCommit = append(sp.parent.onCommit, onCommit...)
+	sp.parent.onRollback = append(sp.parent.onRollback, onRollback...)
+	return nil
+}
+
+// exec executes the given savepoint statement.
+func (sp *savepoint) exec(stmt string) error {
+	return sp.Exec(sp.ctx, stmt+" "+sp.name, []any{}, nil)
+}
@@ -3,8 +3,10 @@
 package ent
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"entgo.io/bug/ent/user"
 	"entgo.io/ent/dialect/sql"
@@ -15,11 +17,15 @@ type User struct {
 	config `json:"-"`
 	// ID of the ent.
 	ID int `json:"id,omitempty"`
+	// DeletedAt holds the value of the "deleted_at" field.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 	// Name holds the value of the "name" field.
 	Name string `json:"name,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the UserQuery when eager-loading is set.
 	Edges UserEdges `json:"edges"`
+
+	selectValues map[string]any
 }
 
 // UserEdges holds the relations/edges for other nodes in the graph.
@@ -49,8 +55,10 @@ func (*User) scanValues(columns []string) ([]any, error) {
 			values[i] = new(sql.NullInt64)
 		case user.FieldName:
 			values[i] = new(sql.NullString)
+		case user.FieldDeletedAt:
+			values[i] = new(sql.NullTime)
 		default:
-			return nil, fmt.Errorf("unexpected column %q for type User", columns[i])
+			values[i] = new(any)
 		}
 	}
 	return values, nil
@@ -70,12 +78,24 @@ func (u *User) assignValues(columns []string, values []any) error {
 				return fmt.Errorf("unexpected type %T for field id", value)
 			}
 			u.ID = int(value.Int64)
+		case user.FieldDeletedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field deleted_at", values[i])
+			} else if value.Valid {
+				u.DeletedAt = new(time.Time)
+				*u.DeletedAt = value.Time
+			}
 		case user.FieldName:
 			if value, ok := values[i].(*sql.NullString); !ok {
 				return fmt.Errorf("unexpected type %T for field name", values[i])
 			} else if value.Valid {
 				u.Name = value.String
 			}
+		default:
+			if u.selectValues == nil {
+				u.selectValues = make(map[string]any)
+			}
+			u.selectValues[columns[i]] = *values[i].(*any)
 		}
 	}
 	return nil
@@ -109,12 +129,45 @@ func (u *User) String() string {
 	var builder strings.Builder
 	builder.WriteString("User(")
 	builder.WriteString(fmt.Sprintf("id=%v, ", u.ID))
+	if v := u.DeletedAt; v != nil {
+		builder.WriteString("deleted_at=")
+		builder.WriteString(v.Format(time.ANSIC))
+	}
+	builder.WriteString(", ")
 	builder.WriteString("name=")
 	builder.WriteString(u.Name)
 	builder.WriteByte(')')
 	return builder.String()
 }
 
+// LoadPosts loads the "posts" edge of the User. If the context carries a Loader
+// (see NewLoaderContext), the load is batched with the concurrent loads of other Users.
+func (u *User) LoadPosts(ctx context.Context) ([]*Post, error) {
+	if l := LoaderFromContext(ctx); l != nil {
+		return l.userPosts.load(ctx, u.ID)
+	}
+	return u.QueryPosts().All(ctx)
+}
+
+// LoadPostsCount counts the "posts" edges of the User, and uses the Loader of the
+// context like LoadPosts.
+func (u *User) LoadPostsCount(ctx context.Context) (int, error) {
+	if l := LoaderFromContext(ctx); l != nil {
+		return l.userPostsCount.load(ctx, u.ID)
+	}
+	return u.QueryPosts().Count(ctx)
+}
+
+// Value returns the value of a column that was selected dynamically (e.g. using Modify), and
+// is not a field of the User.
+func (u *User) Value(name string) (Value, error) {
+	v, ok := u.selectValues[name]
+	if !ok {
+		return nil, fmt.Errorf("ent: value %q was not selected for type User", name)
+	}
+	return v, nil
+}
+
 // Users is a parsable slice of User.
 type Users []*User
 
@@ -2,11 +2,17 @@
 
 package user
 
+import (
+	"entgo.io/ent/dialect/sql"
+)
+
 const (
 	// Label holds the string label denoting the user type in the database.
 	Label = "user"
 	// FieldID holds the string denoting the id field in the database.
 	FieldID = "id"
+	// FieldDeletedAt holds the string denoting the deleted_at field in the database.
+	FieldDeletedAt = "deleted_at"
 	// FieldName holds the string denoting the name field in the database.
 	FieldName = "name"
 	// EdgePosts holds the string denoting the posts edge name in mutations.
@@ -25,6 +31,7 @@ const (
 // Columns holds all SQL columns for user fields.
 var Columns = []string{
 	FieldID,
+	FieldDeletedAt,
 	FieldName,
 }
 
@@ -37,3 +44,27 @@ func ValidColumn(column string) bool {
 	}
 	return false
 }
+
+// PostsDeletedAtColumn is the soft-deletion column of the Post entity of the "posts" edge.
+// It exists in this package in order to avoid circular dependency with the "post" package.
+const PostsDeletedAtColumn = "deleted_at"
+
+// ByPostsCount orders the results by the number of their "posts" edges.
+// Soft-deleted posts are not counted.
+func ByPostsCount(desc bool) func(*sql.Selector) {
+	return func(s *sql.Selector) {
+		t := sql.Table(PostsTable)
+		count := sql.Select(sql.Count("*")).
+			From(t).
+			Where(sql.ColumnsEQ(t.C(PostsColumn), s.C(FieldID)))
+		count.Where(sql.IsNull(t.C(PostsDeletedAtColumn)))
+		s.OrderExpr(sql.ExprFunc(func(b *sql.Builder) {
+			b.Nested(func(b *sql.Builder) {
+				b.Join(count)
+			})
+			if desc {
+				b.WriteString(" DESC")
+			}
+		}))
+	}
+}
@@ -3,6 +3,8 @@
 package user
 
 import (
+	"time"
+
 	"entgo.io/bug/ent/predicate"
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
@@ -36,7 +38,7 @@ func IDIn(ids ...int) predicate.User {
 		for i := range v {
 			v[i] = ids[i]
 		}
-		s.Where(sql.In(s.C(FieldID), v...))
+		s.Where(predicate.In(s.C(FieldID), false, v...))
 	})
 }
 
@@ -47,7 +49,7 @@ func IDNotIn(ids ...int) predicate.User {
 		for i := range v {
 			v[i] = ids[i]
 		}
-		s.Where(sql.NotIn(s.C(FieldID), v...))
+		s.Where(predicate.In(s.C(FieldID), true, v...))
 	})
 }
 
@@ -79,6 +81,13 @@ func IDLTE(id int) predicate.User {
 	})
 }
 
+// DeletedAt applies equality check predicate on the "deleted_at" field. It's identical to DeletedAtEQ.
+func DeletedAt(v time.Time) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(sql.EQ(s.C(FieldDeletedAt), v))
+	})
+}
+
 // Name applies equality check predicate on the "name" field. It's identical to NameEQ.
 func Name(v string) predicate.User {
 	return predicate.User(func(s *sql.Selector) {
@@ -86,6 +95,84 @@ func Name(v string) predicate.User {
 	})
 }
 
+// DeletedAtEQ applies the EQ predicate on the "deleted_at" field.
+func DeletedAtEQ(v time.Time) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(sql.EQ(s.C(FieldDeletedAt), v))
+	})
+}
+
+// DeletedAtNEQ applies the NEQ predicate on the "deleted_at" field.
+func DeletedAtNEQ(v time.Time) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(sql.NEQ(s.C(FieldDeletedAt), v))
+	})
+}
+
+// DeletedAtIn applies the In predicate on the "deleted_at" field.
+func DeletedAtIn(vs ...time.Time) predicate.User {
+	v := make([]any, len(vs))
+	for i := range v {
+		v[i] = vs[i]
+	}
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(predicate.In(s.C(FieldDeletedAt), false, v...))
+	})
+}
+
+// DeletedAtNotIn applies the NotIn predicate on the "deleted_at" field.
+func DeletedAtNotIn(vs ...time.Time) predicate.User {
+	v := make([]any, len(vs))
+	for i := range v {
+		v[i] = vs[i]
+	}
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(predicate.In(s.C(FieldDeletedAt), true, v...))
+	})
+}
+
+// DeletedAtGT applies the GT predicate on the "deleted_at" field.
+func DeletedAtGT(v time.Time) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(sql.GT(s.C(FieldDeletedAt), v))
+	})
+}
+
+// DeletedAtGTE applies the GTE predicate on the "deleted_at" field.
+func DeletedAtGTE(v time.Time) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(sql.GTE(s.C(FieldDeletedAt), v))
+	})
+}
+
+// DeletedAtLT applies the LT predicate on the "deleted_at" field.
+func DeletedAtLT(v time.Time) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(sql.LT(s.C(FieldDeletedAt), v))
+	})
+}
+
+// DeletedAtLTE applies the LTE predicate on the "deleted_at" field.
+func DeletedAtLTE(v time.Time) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(sql.LTE(s.C(FieldDeletedAt), v))
+	})
+}
+
+// DeletedAtIsNil applies the IsNil predicate on the "deleted_at" field.
+func DeletedAtIsNil() predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(sql.IsNull(s.C(FieldDeletedAt)))
+	})
+}
+
+// DeletedAtNotNil applies the NotNil predicate on the "deleted_at" field.
+func DeletedAtNotNil() predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(sql.NotNull(s.C(FieldDeletedAt)))
+	})
+}
+
 // NameEQ applies the EQ predicate on the "name" field.
 func NameEQ(v string) predicate.User {
 	return predicate.User(func(s *sql.Selector) {
@@ -107,7 +194,7 @@ func NameIn(vs ...string) predicate.User {
 		v[i] = vs[i]
 	}
 	return predicate.User(func(s *sql.Selector) {
-		s.Where(sql.In(s.C(FieldName), v...))
+		s.Where(predicate.In(s.C(FieldName), false, v...))
 	})
 }
 
@@ -118,7 +205,7 @@ func NameNotIn(vs ...string) predicate.User {
 		v[i] = vs[i]
 	}
 	return predicate.User(func(s *sql.Selector) {
-		s.Where(sql.NotIn(s.C(FieldName), v...))
+		s.Where(predicate.In(s.C(FieldName), true, v...))
 	})
 }
 
@@ -187,14 +274,7 @@ func NameContainsFold(v string) predicate.User {
 
 // HasPosts applies the HasEdge predicate on the "posts" edge.
 func HasPosts() predicate.User {
-	return predicate.User(func(s *sql.Selector) {
-		step := sqlgraph.NewStep(
-			sqlgraph.From(Table, FieldID),
-			sqlgraph.To(PostsTable, FieldID),
-			sqlgraph.Edge(sqlgraph.O2M, false, PostsTable, PostsColumn),
-		)
-		sqlgraph.HasNeighbors(s, step)
-	})
+	return predicate.User(HasPostsWith())
 }
 
 // HasPostsWith applies the HasEdge predicate on the "posts" edge with a given conditions (other predicates).
@@ -206,6 +286,7 @@ func HasPostsWith(preds ...predicate.Post) predicate.User {
 			sqlgraph.Edge(sqlgraph.O2M, false, PostsTable, PostsColumn),
 		)
 		sqlgraph.HasNeighborsWith(s, step, func(s *sql.Selector) {
+			s.Where(sql.IsNull(s.C(PostsDeletedAtColumn)))
 			for _, p := range preds {
 				p(s)
 			}
@@ -244,3 +325,51 @@ func Not(p predicate.User) predicate.User {
 		p(s.Not())
 	})
 }
+
+// IDInQuery applies the In predicate on the ID field, with the IDs that are selected
+// by the subquery (e.g. UserQuery.SelectIDs).
+func IDInQuery(q predicate.Subquery[int]) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldID), false, q))
+	})
+}
+
+// IDNotInQuery applies the NotIn predicate on the ID field, with the IDs that are selected
+// by the subquery (e.g. UserQuery.SelectIDs).
+func IDNotInQuery(q predicate.Subquery[int]) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldID), true, q))
+	})
+}
+
+// DeletedAtInQuery applies the In predicate on the "deleted_at" field, with the values that
+// are selected by the subquery.
+func DeletedAtInQuery(q predicate.Subquery[time.Time]) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldDeletedAt), false, q))
+	})
+}
+
+// DeletedAtNotInQuery applies the NotIn predicate on the "deleted_at" field, with the values that
+// are selected by the subquery.
+func DeletedAtNotInQuery(q predicate.Subquery[time.Time]) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldDeletedAt), true, q))
+	})
+}
+
+// NameInQuery applies the In predicate on the "name" field, with the values that
+// are selected by the subquery.
+func NameInQuery(q predicate.Subquery[string]) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldName), false, q))
+	})
+}
+
+// NameNotInQuery applies the NotIn predicate on the "name" field, with the values that
+// are selected by the subquery.
+func NameNotInQuery(q predicate.Subquery[string]) predicate.User {
+	return predicate.User(func(s *sql.Selector) {
+		s.Where(predicate.InQuery(s.Context(), s.C(FieldName), true, q))
+	})
+}
@@ -4,7 +4,9 @@ package ent
 
 import (
 	"context"
+	"database/sql/driver"
 	"fmt"
+	"time"
 
 	"entgo.io/bug/ent/predicate"
 	"entgo.io/bug/ent/user"
@@ -18,6 +20,7 @@ type UserDelete struct {
 	config
 	hooks    []Hook
 	mutation *UserMutation
+	cascade  bool
 }
 
 // Where appends a list predicates to the UserDelete builder.
@@ -26,6 +29,15 @@ func (ud *UserDelete) Where(ps ...predicate.User) *UserDelete {
 	return ud
 }
 
+// Cascade configures the builder to apply the ON DELETE actions of the User edges
+// ("posts") in application code, in the same transaction as the deletion
+// itself. It is useful for databases that do not enforce foreign-keys, like SQLite
+// connections opened without "_fk=1".
+func (ud *UserDelete) Cascade() *UserDelete {
+	ud.cascade = true
+	return ud
+}
+
 // Exec executes the deletion query and returns how many vertices were deleted.
 func (ud *UserDelete) Exec(ctx context.Context) (int, error) {
 	var (
@@ -68,34 +80,150 @@ func (ud *UserDelete) ExecX(ctx context.Context) int {
 }
 
 func (ud *UserDelete) sqlExec(ctx context.Context) (int, error) {
-	_spec := &sqlgraph.DeleteSpec{
+	if ud.cascade {
+		return ud.cascadeExec(ctx)
+	}
+	_spec := &sqlgraph.UpdateSpec{
 		Node: &sqlgraph.NodeSpec{
-			Table: user.Table,
+			Table:   user.Table,
+			Columns: user.Columns,
 			ID: &sqlgraph.FieldSpec{
 				Type:   field.TypeInt,
 				Column: user.FieldID,
 			},
 		},
 	}
-	if ps := ud.mutation.predicates; len(ps) > 0 {
-		_spec.Predicate = func(selector *sql.Selector) {
-			for i := range ps {
-				ps[i](selector)
-			}
+	_spec.Fields.Set = append(_spec.Fields.Set, &sqlgraph.FieldSpec{
+		Type:   field.TypeTime,
+		Value:  time.Now(),
+		Column: user.FieldDeletedAt,
+	})
+	ps := ud.mutation.predicates
+	_spec.Predicate = func(selector *sql.Selector) {
+		selector.Where(sql.IsNull(selector.C(user.FieldDeletedAt)))
+		for i := range ps {
+			ps[i](selector)
 		}
 	}
-	affected, err := sqlgraph.DeleteNodes(ctx, ud.driver, _spec)
+	affected, err := sqlgraph.UpdateNodes(ctx, ud.driver, _spec)
 	if err != nil && sqlgraph.IsConstraintError(err) {
 		err = &ConstraintError{msg: err.Error(), wrap: err}
 	}
 	return affected, err
 }
 
+// cascadeExec applies the ON DELETE actions of the User edges on the matched
+// nodes, and then deletes them. All statements are executed in one transaction.
+func (ud *UserDelete) cascadeExec(ctx context.Context) (int, error) {
+	tx, err := newTx(ctx, ud.config)
+	if err != nil {
+		return 0, err
+	}
+	cfg := ud.config
+	cfg.driver = tx
+	affected, err := func() (int, error) {
+		ids, err := (&UserQuery{config: cfg, predicates: ud.mutation.predicates}).IDs(ctx)
+		if err != nil || len(ids) == 0 {
+			return 0, err
+		}
+		fks := make([]driver.Value, len(ids))
+		for i := range ids {
+			fks[i] = ids[i]
+		}
+		// The actions are applied on chunks of the foreign-keys that fit into one IN list.
+		for _, fks := range chunkIn(ud.driver.Dialect(), fks) {
+			posts := predicate.Post(func(s *sql.Selector) {
+				s.Where(sql.InValues(s.C(user.PostsColumn), fks...))
+			})
+			if _, err := NewPostClient(cfg).Delete().Where(posts).Exec(ctx); err != nil {
+				return 0, fmt.Errorf("ent: cascade edge %q: %w", user.EdgePosts, err)
+			}
+		}
+		m := newUserMutation(cfg, OpDelete)
+		m.Where(user.IDIn(ids...))
+		return (&UserDelete{config: cfg, mutation: m}).sqlExec(ctx)
+	}()
+	if err != nil {
+		if rerr := tx.rollback(); rerr != nil {
+			err = fmt.Errorf("%w: rolling back transaction: %v", err, rerr)
+		}
+		return 0, err
+	}
+	if err := tx.commit(); err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
 // UserDeleteOne is the builder for deleting a single User entity.
 type UserDeleteOne struct {
 	ud *UserDelete
 }
 
+// Cascade configures the builder to apply the ON DELETE actions of the User edges
+// in application code. See UserDelete.Cascade for more info.
+func (udo *UserDeleteOne) Cascade() *UserDeleteOne {
+	udo.ud.Cascade()
+	return udo
+}
+
+// ReassignPosts moves the "posts" of the User to the User with the given id, and then
+// deletes it. Both operations are executed in one transaction, and the number of reassigned
+// posts is returned. Soft-deleted posts are reassigned as well. The posts are updated
+// using the PostUpdate builder, and therefore, the Post hooks are executed.
+func (udo *UserDeleteOne) ReassignPosts(ctx context.Context, to int) (int, error) {
+	tx, err := newTx(ctx, udo.ud.config)
+	if err != nil {
+		return 0, err
+	}
+	cfg := udo.ud.config
+	cfg.driver = tx
+	n, err := func() (int, error) {
+		id, err := (&UserQuery{config: cfg, predicates: udo.ud.mutation.predicates}).OnlyID(ctx)
+		switch {
+		case err != nil:
+			return 0, err
+		case id == to:
+			return 0, fmt.Errorf("ent: cannot reassign posts of user %v to itself", id)
+		}
+		switch exist, err := NewUserClient(cfg).Query().Where(user.ID(to)).Exist(ctx); {
+		case err != nil:
+			return 0, err
+		case !exist:
+			return 0, &NotFoundError{user.Label}
+		}
+		n, err := NewPostClient(cfg).Update().
+			Where(func(s *sql.Selector) {
+				s.Where(sql.EQ(s.C(user.PostsColumn), id))
+			}).
+			SetCreatorID(to).
+			Save(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("ent: reassign edge %q: %w", user.EdgePosts, err)
+		}
+		// The deletion uses a mutation of its own, as the mutation of the builder must not be
+		// bound to the transaction after it ends.
+		m := newUserMutation(cfg, OpDeleteOne, withUserID(id))
+		m.Where(udo.ud.mutation.predicates...)
+		ud := *udo.ud
+		ud.config, ud.mutation = cfg, m
+		if err := (&UserDeleteOne{&ud}).Exec(ctx); err != nil {
+			return 0, err
+		}
+		return n, nil
+	}()
+	if err != nil {
+		if rerr := tx.rollback(); rerr != nil {
+			err = fmt.Errorf("%w: rolling back transaction: %v", err, rerr)
+		}
+		return 0, err
+	}
+	if err := tx.commit(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
 // Exec executes the deletion query.
 func (udo *UserDeleteOne) Exec(ctx context.Context) error {
 	n, err := udo.ud.Exec(ctx)
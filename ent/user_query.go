@@ -5,12 +5,15 @@ package ent
 import (
 	"context"
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"math"
+	"time"
 
 	"entgo.io/bug/ent/post"
 	"entgo.io/bug/ent/predicate"
 	"entgo.io/bug/ent/user"
+	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/schema/field"
@@ -26,6 +29,14 @@ type UserQuery struct {
 	fields     []string
 	predicates []predicate.User
 	withPosts  *PostQuery
+	eagerJoin  bool
+	locked     bool
+	// lock sets the locking clause of the query on a selector, that locks the rows of
+	// the given tables, or of all tables if none are given.
+	lock        func(*sql.Selector, ...string)
+	modifiers   []func(*sql.Selector)
+	withDeleted bool
+	timeout     time.Duration
 	// intermediate query (i.e. traversal path).
 	sql  *sql.Selector
 	path func(context.Context) (*sql.Selector, error)
@@ -259,18 +270,15 @@ func (uq *UserQuery) Clone() *UserQuery {
 	if uq == nil {
 		return nil
 	}
-	return &UserQuery{
-		config:     uq.config,
-		limit:      uq.limit,
-		offset:     uq.offset,
-		order:      append([]OrderFunc{}, uq.order...),
-		predicates: append([]predicate.User{}, uq.predicates...),
-		withPosts:  uq.withPosts.Clone(),
-		// clone intermediate query.
-		sql:    uq.sql.Clone(),
-		path:   uq.path,
-		unique: uq.unique,
-	}
+	clone := *uq
+	clone.order = append([]OrderFunc{}, uq.order...)
+	clone.fields = append([]string{}, uq.fields...)
+	clone.predicates = append([]predicate.User{}, uq.predicates...)
+	clone.withPosts = uq.withPosts.Clone()
+	clone.modifiers = append([]func(*sql.Selector){}, uq.modifiers...)
+	// clone intermediate query.
+	clone.sql = uq.sql.Clone()
+	return &clone
 }
 
 // WithPosts tells the query-builder to eager-load the nodes that are connected to
@@ -290,17 +298,17 @@ func (uq *UserQuery) WithPosts(opts ...func(*PostQuery)) *UserQuery {
 // Example:
 //
 //	var v []struct {
-//		Name string `json:"name,omitempty"`
+//		DeletedAt time.Time `json:"deleted_at,omitempty"`
 //		Count int `json:"count,omitempty"`
 //	}
 //
 //	client.User.Query().
-//		GroupBy(user.FieldName).
+//		GroupBy(user.FieldDeletedAt).
 //		Aggregate(ent.Count()).
 //		Scan(ctx, &v)
 //
 func (uq *UserQuery) GroupBy(field string, fields ...string) *UserGroupBy {
-	grbuild := &UserGroupBy{config: uq.config}
+	grbuild := &UserGroupBy{config: uq.config, timeout: uq.timeout}
 	grbuild.fields = append([]string{field}, fields...)
 	grbuild.path = func(ctx context.Context) (prev *sql.Selector, err error) {
 		if err := uq.prepareQuery(ctx); err != nil {
@@ -319,11 +327,11 @@ func (uq *UserQuery) GroupBy(field string, fields ...string) *UserGroupBy {
 // Example:
 //
 //	var v []struct {
-//		Name string `json:"name,omitempty"`
+//		DeletedAt time.Time `json:"deleted_at,omitempty"`
 //	}
 //
 //	client.User.Query().
-//		Select(user.FieldName).
+//		Select(user.FieldDeletedAt).
 //		Scan(ctx, &v)
 //
 func (uq *UserQuery) Select(fields ...string) *UserSelect {
@@ -340,6 +348,9 @@ func (uq *UserQuery) prepareQuery(ctx context.Context) error {
 			return &ValidationError{Name: f, err: fmt.Errorf("ent: invalid field %q for query", f)}
 		}
 	}
+	if uq.locked && uq.driver.Dialect() == dialect.SQLite {
+		return errors.New("ent: row-level locking is not supported by sqlite")
+	}
 	if uq.path != nil {
 		prev, err := uq.path(ctx)
 		if err != nil {
@@ -351,6 +362,9 @@ func (uq *UserQuery) prepareQuery(ctx context.Context) error {
 }
 
 func (uq *UserQuery) sqlAll(ctx context.Context, hooks ...queryHook) ([]*User, error) {
+	ctx = newOperationContext(ctx, "User", "All")
+	ctx, cancel := newTimeoutContext(ctx, uq.timeout)
+	defer cancel()
 	var (
 		nodes       = []*User{}
 		_spec       = uq.querySpec()
@@ -367,6 +381,18 @@ func (uq *UserQuery) sqlAll(ctx context.Context, hooks ...queryHook) ([]*User, e
 		node.Edges.loadedTypes = loadedTypes
 		return node.assignValues(columns, values)
 	}
+	if len(uq.modifiers) > 0 {
+		_spec.Modifiers = uq.modifiers
+	}
+	if !uq.withDeleted {
+		pred := _spec.Predicate
+		_spec.Predicate = func(selector *sql.Selector) {
+			selector.Where(sql.IsNull(selector.C(user.FieldDeletedAt)))
+			if pred != nil {
+				pred(selector)
+			}
+		}
+	}
 	for i := range hooks {
 		hooks[i](ctx, _spec)
 	}
@@ -387,6 +413,7 @@ func (uq *UserQuery) sqlAll(ctx context.Context, hooks ...queryHook) ([]*User, e
 }
 
 func (uq *UserQuery) loadPosts(ctx context.Context, query *PostQuery, nodes []*User, init func(*User), assign func(*User, *Post)) error {
+	ctx = newLoadContext(ctx, "Post")
 	fks := make([]driver.Value, 0, len(nodes))
 	nodeids := make(map[int]*User)
 	for i := range nodes {
@@ -396,26 +423,47 @@ func (uq *UserQuery) loadPosts(ctx context.Context, query *PostQuery, nodes []*U
 			init(nodes[i])
 		}
 	}
-	query.Where(predicate.Post(func(s *sql.Selector) {
-		s.Where(sql.InValues(user.PostsColumn, fks...))
-	}))
-	neighbors, err := query.All(ctx)
-	if err != nil {
-		return err
-	}
-	for _, n := range neighbors {
-		fk := n.UserID
-		node, ok := nodeids[fk]
-		if !ok {
-			return fmt.Errorf(`unexpected foreign-key "user_id" returned %v for node %v`, fk, n.ID)
+	preds := query.predicates
+	for _, fks := range chunkIn(query.driver.Dialect(), fks) {
+		query.predicates = append(preds[:len(preds):len(preds)], predicate.Post(func(s *sql.Selector) {
+			s.Where(sql.InValues(user.PostsColumn, fks...))
+		}))
+		neighbors, err := query.All(ctx)
+		if err != nil {
+			return err
+		}
+		for _, n := range neighbors {
+			fk := n.UserID
+			node, ok := nodeids[fk]
+			if !ok {
+				return fmt.Errorf(`unexpected foreign-key "user_id" returned %v for node %v`, fk, n.ID)
+			}
+			assign(node, n)
 		}
-		assign(node, n)
 	}
 	return nil
 }
 
 func (uq *UserQuery) sqlCount(ctx context.Context) (int, error) {
+	ctx = newOperationContext(ctx, "User", "Count")
+	ctx, cancel := newTimeoutContext(ctx, uq.timeout)
+	defer cancel()
+	if uq.locked {
+		return uq.sqlLockedCount(ctx)
+	}
 	_spec := uq.querySpec()
+	if len(uq.modifiers) > 0 {
+		_spec.Modifiers = uq.modifiers
+	}
+	if !uq.withDeleted {
+		pred := _spec.Predicate
+		_spec.Predicate = func(selector *sql.Selector) {
+			selector.Where(sql.IsNull(selector.C(user.FieldDeletedAt)))
+			if pred != nil {
+				pred(selector)
+			}
+		}
+	}
 	_spec.Node.Columns = uq.fields
 	if len(uq.fields) > 0 {
 		_spec.Unique = uq.unique != nil && *uq.unique
@@ -424,6 +472,7 @@ func (uq *UserQuery) sqlCount(ctx context.Context) (int, error) {
 }
 
 func (uq *UserQuery) sqlExist(ctx context.Context) (bool, error) {
+	ctx = newOperationContext(ctx, "User", "Exist")
 	switch _, err := uq.FirstID(ctx); {
 	case IsNotFound(err):
 		return false, nil
@@ -497,6 +546,13 @@ func (uq *UserQuery) sqlQuery(ctx context.Context) *sql.Selector {
 	if uq.unique != nil && *uq.unique {
 		selector.Distinct()
 	}
+	selector.WithContext(ctx)
+	for _, m := range uq.modifiers {
+		m(selector)
+	}
+	if !uq.withDeleted {
+		selector.Where(sql.IsNull(selector.C(user.FieldDeletedAt)))
+	}
 	for _, p := range uq.predicates {
 		p(selector)
 	}
@@ -514,12 +570,413 @@ func (uq *UserQuery) sqlQuery(ctx context.Context) *sql.Selector {
 	return selector
 }
 
+// ForEachBatch executes the query in pages of the given size, ordered by the User ID,
+// and calls fn with each page. It stops on the first error returned by fn. Order, Limit and
+// Offset are not supported. The timeout of the query applies to the loading of each page.
+func (uq *UserQuery) ForEachBatch(ctx context.Context, size int, fn func([]*User) error) error {
+	return uq.forEachBatch(ctx, size, func(page func(config) ([]*User, error)) ([]*User, error) {
+		nodes, err := page(uq.config)
+		if err == nil && len(nodes) > 0 {
+			err = fn(nodes)
+		}
+		return nodes, err
+	})
+}
+
+// ForEachBatchTx is like ForEachBatch, but each page is loaded and processed in its own
+// transaction, which is committed if fn succeeds and rolled back otherwise. The Users,
+// including the edges that are eager-loaded with them, are loaded in the transaction and bound to
+// it, and should not be used after fn returns.
+func (uq *UserQuery) ForEachBatchTx(ctx context.Context, size int, fn func(*Tx, []*User) error) error {
+	client := &Client{config: uq.config}
+	return uq.forEachBatch(ctx, size, func(page func(config) ([]*User, error)) ([]*User, error) {
+		tx, err := client.Tx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		nodes, err := page(tx.config)
+		if err == nil && len(nodes) > 0 {
+			err = fn(tx, nodes)
+		}
+		if err != nil {
+			if rerr := tx.Rollback(); rerr != nil {
+				err = fmt.Errorf("%w: rolling back transaction: %v", err, rerr)
+			}
+			return nil, err
+		}
+		return nodes, tx.Commit()
+	})
+}
+
+// forEachBatch pages over the results of the query. batch is called for each page with
+// a function that loads it using the given config, and returns the loaded Users.
+// The pages are loaded by a clone of the query, which is left unchanged.
+func (uq *UserQuery) forEachBatch(ctx context.Context, size int, batch func(func(config) ([]*User, error)) ([]*User, error)) error {
+	switch {
+	case size <= 0:
+		return fmt.Errorf("ent: invalid batch size %d", size)
+	case len(uq.order) > 0 || uq.limit != nil || uq.offset != nil:
+		return errors.New("ent: UserQuery.ForEachBatch does not support Order, Limit and Offset")
+	}
+	ctx = newOperationContext(ctx, "User", "ForEachBatch")
+	preds := uq.predicates
+	var last *int
+	for {
+		nodes, err := batch(func(cfg config) ([]*User, error) {
+			query := uq.Clone()
+			query.setConfig(cfg)
+			query.predicates = preds[:len(preds):len(preds)]
+			if last != nil {
+				query.predicates = append(query.predicates, user.IDGT(*last))
+			}
+			return query.Order(Asc(user.FieldID)).Limit(size).All(ctx)
+		})
+		if err != nil || len(nodes) < size {
+			return err
+		}
+		last = &nodes[len(nodes)-1].ID
+	}
+}
+
+// setConfig sets the config of the query, and of the queries of the edges it eager-loads.
+func (uq *UserQuery) setConfig(cfg config) {
+	uq.config = cfg
+	if uq.withPosts != nil {
+		uq.withPosts.setConfig(cfg)
+	}
+}
+
+// EagerJoin configures the query-builder to eager-load its unique edges using a LEFT JOIN,
+// instead of a second query. Edges that are eager-loaded with options that cannot be applied
+// to a joined table (field selection, pagination or eager-loading of their own edges), and
+// non-unique edges are loaded as usual. Note that joined neighbors are not shared between
+// the nodes that point to them.
+func (uq *UserQuery) EagerJoin() *UserQuery {
+	uq.eagerJoin = true
+	return uq
+}
+
+// joinable reports if the query can be used as a joined table for eager-loading.
+func (uq *UserQuery) joinable() bool {
+	return len(uq.fields) == 0 && uq.limit == nil && uq.offset == nil && uq.withPosts == nil
+}
+
+// Iter executes the query and returns an iterator that scans the Users one at a
+// time. The iterator holds a database connection until it is exhausted or closed, so it must
+// always be closed. The timeout of the query lasts until the iterator is closed. Eager-loading
+// of edges is not supported by iterators.
+func (uq *UserQuery) Iter(ctx context.Context) (_ *UserIterator, err error) {
+	if uq.withPosts != nil {
+		return nil, errors.New("ent: eager-loading posts is not supported by UserQuery.Iter")
+	}
+	ctx = newOperationContext(ctx, "User", "Iter")
+	ctx, cancel := newTimeoutContext(ctx, uq.timeout)
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+	if err := uq.prepareQuery(ctx); err != nil {
+		return nil, err
+	}
+	selector := uq.sqlQuery(ctx)
+	if fields := uq.fields; len(fields) > 0 {
+		columns := []string{user.FieldID}
+		for i := range fields {
+			if fields[i] != user.FieldID {
+				columns = append(columns, fields[i])
+			}
+		}
+		selector.Select(selector.Columns(columns...)...)
+	}
+	if unique := uq.unique; unique == nil || *unique {
+		selector.Distinct()
+	}
+	if err := selector.Err(); err != nil {
+		return nil, err
+	}
+	rows := &sql.Rows{}
+	query, args := selector.Query()
+	if err := uq.driver.Query(ctx, query, args, rows); err != nil {
+		return nil, err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return &UserIterator{config: uq.config, rows: rows, columns: columns, cancel: cancel}, nil
+}
+
+// Each executes the query and calls fn for each of the Users it returns,
+// while they are being scanned. It stops on the first error returned by fn.
+func (uq *UserQuery) Each(ctx context.Context, fn func(*User) error) error {
+	it, err := uq.Iter(ctx)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for it.Next() {
+		if err := fn(it.Value()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// UserIterator iterates over the results of a UserQuery. See UserQuery.Iter for more info.
+type UserIterator struct {
+	config
+	rows    *sql.Rows
+	columns []string
+	node    *User
+	err     error
+	// cancel cancels the timeout of the query.
+	cancel context.CancelFunc
+}
+
+// Next scans the next User, and reports if there was one. It returns false
+// when the results are exhausted or an error occurred, and Err should be checked.
+func (it *UserIterator) Next() bool {
+	if it.err != nil || !it.rows.Next() {
+		return false
+	}
+	values, err := (*User).scanValues(nil, it.columns)
+	if err == nil {
+		err = it.rows.Scan(values...)
+	}
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.node = &User{config: it.config}
+	if it.err = it.node.assignValues(it.columns, values); it.err != nil {
+		return false
+	}
+	return true
+}
+
+// Value returns the User that was scanned by the last call to Next.
+func (it *UserIterator) Value() *User {
+	return it.node
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *UserIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.rows.Err()
+}
+
+// Close closes the iterator and releases its database connection.
+func (it *UserIterator) Close() error {
+	defer it.cancel()
+	return it.rows.Close()
+}
+
+// ForUpdate locks the selected rows against concurrent updates, and prevent them from being
+// updated, deleted or "selected ... for update" by other sessions, until the transaction is
+// either committed or rolled-back. The lock can be configured to fail or to skip locked rows,
+// instead of waiting for them, using sql.WithLockAction(sql.NoWait) or sql.WithLockAction(sql.SkipLocked)
+// (MySQL 8 and PostgreSQL only).
+//
+// If the query eager-loads its edges using a LEFT JOIN (see EagerJoin), only its own rows are
+// locked (FOR UPDATE OF). Row locks are not supported by SQLite, which locks the whole database
+// for writing, and the query fails with an error.
+func (uq *UserQuery) ForUpdate(opts ...sql.LockOption) *UserQuery {
+	if uq.driver.Dialect() == dialect.Postgres {
+		uq.Unique(false)
+	}
+	lock := func(s *sql.Selector, tables ...string) {
+		s.ForUpdate(append([]sql.LockOption{sql.WithLockTables(tables...)}, opts...)...)
+	}
+	uq.locked, uq.lock = true, lock
+	uq.modifiers = append(uq.modifiers, func(s *sql.Selector) {
+		lock(s)
+	})
+	return uq
+}
+
+// ForShare behaves similarly to ForUpdate, except that it acquires a shared mode lock
+// on any rows that are read. Other sessions can read the rows, but cannot modify them
+// until your transaction commits. In MySQL, a lock without options of a query that is
+// not joined is written as LOCK IN SHARE MODE, which is supported by all versions.
+func (uq *UserQuery) ForShare(opts ...sql.LockOption) *UserQuery {
+	if uq.driver.Dialect() == dialect.Postgres {
+		uq.Unique(false)
+	}
+	mysql := uq.driver.Dialect() == dialect.MySQL
+	lock := func(s *sql.Selector, tables ...string) {
+		if mysql && len(opts) == 0 && len(tables) == 0 {
+			s.ForShare(sql.WithLockClause("LOCK IN SHARE MODE"))
+			return
+		}
+		s.ForShare(append([]sql.LockOption{sql.WithLockTables(tables...)}, opts...)...)
+	}
+	uq.locked, uq.lock = true, lock
+	uq.modifiers = append(uq.modifiers, func(s *sql.Selector) {
+		lock(s)
+	})
+	return uq
+}
+
+// sqlLockedCount counts the rows of a query with a lock, by locking them in a subquery:
+// SELECT COUNT(*) FROM (SELECT ... FOR UPDATE).
+func (uq *UserQuery) sqlLockedCount(ctx context.Context) (int, error) {
+	selector := uq.sqlQuery(ctx)
+	if len(uq.fields) == 0 {
+		selector.Select(selector.C(user.FieldID))
+		if uq.unique == nil {
+			selector.Distinct()
+		}
+	}
+	query, args := sql.Dialect(uq.driver.Dialect()).
+		Select(sql.Count("*")).
+		From(selector.As("t")).
+		Query()
+	rows := &sql.Rows{}
+	if err := uq.driver.Query(ctx, query, args, rows); err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	return sql.ScanInt(rows)
+}
+
+// Modify adds a query modifier for attaching custom logic to queries.
+func (uq *UserQuery) Modify(modifiers ...func(s *sql.Selector)) *UserSelect {
+	uq.modifiers = append(uq.modifiers, modifiers...)
+	return uq.Select()
+}
+
+// project calls scan with the selector of a projection of the query, and its config, under
+// the timeout of the query. See Project.
+func (uq *UserQuery) project(ctx context.Context, scan func(context.Context, *sql.Selector, config) error) error {
+	ctx = newOperationContext(ctx, "User", "Project")
+	ctx, cancel := newTimeoutContext(ctx, uq.timeout)
+	defer cancel()
+	if err := uq.prepareQuery(ctx); err != nil {
+		return err
+	}
+	return scan(ctx, uq.sqlQuery(ctx), uq.config)
+}
+
+// Union returns a query of the Users that are returned by the query or by one of
+// the other queries. The queries should not be used after, and should not have Order, Limit
+// or Offset. The Timeout, the modifiers (e.g. Modify) and the eager-loading of the query apply
+// to the returned query, and the other queries should not have them. The returned query
+// supports Order, Limit, Offset and other options, like any other query.
+func (uq *UserQuery) Union(others ...*UserQuery) *UserQuery {
+	return uq.setOp(opUnion, others)
+}
+
+// Intersect returns a query of the Users that are returned by the query and by all
+// of the other queries. See Union for more info.
+func (uq *UserQuery) Intersect(others ...*UserQuery) *UserQuery {
+	return uq.setOp(opIntersect, others)
+}
+
+// Except returns a query of the Users that are returned by the query and by none
+// of the other queries. See Union for more info.
+func (uq *UserQuery) Except(others ...*UserQuery) *UserQuery {
+	return uq.setOp(opExcept, others)
+}
+
+// setOp returns a query of the Users whose IDs are returned by the set operation on
+// the query and the other queries. The options of the query that apply to the returned nodes
+// are moved to the returned query.
+func (uq *UserQuery) setOp(op setOp, others []*UserQuery) *UserQuery {
+	query := &UserQuery{
+		config:      uq.config,
+		timeout:     uq.timeout,
+		modifiers:   uq.modifiers,
+		eagerJoin:   uq.eagerJoin,
+		withPosts:   uq.withPosts,
+		locked:      uq.locked,
+		lock:        uq.lock,
+		withDeleted: true,
+	}
+	operand := &UserQuery{
+		config:      uq.config,
+		predicates:  uq.predicates,
+		order:       uq.order,
+		limit:       uq.limit,
+		offset:      uq.offset,
+		sql:         uq.sql,
+		path:        uq.path,
+		withDeleted: uq.withDeleted,
+	}
+	query.path = func(ctx context.Context) (*sql.Selector, error) {
+		selectors := make([]*sql.Selector, 0, len(others)+1)
+		for _, q := range append([]*UserQuery{operand}, others...) {
+			switch {
+			case len(q.order) > 0 || q.limit != nil || q.offset != nil:
+				return nil, fmt.Errorf("ent: operands of %s do not support Order, Limit and Offset", op)
+			case q.timeout != 0 || len(q.modifiers) > 0 || q.withPosts != nil:
+				return nil, fmt.Errorf("ent: other operands of %s do not support Timeout, modifiers and eager-loading", op)
+			}
+			selector, err := q.subquery(ctx, user.FieldID)
+			if err != nil {
+				return nil, err
+			}
+			selectors = append(selectors, selector)
+		}
+		builder := sql.Dialect(uq.driver.Dialect())
+		t1 := builder.Table(user.Table)
+		return builder.Select().From(t1).WithContext(ctx).Where(setIn(op, t1.C(user.FieldID), selectors)), nil
+	}
+	return query
+}
+
+// IncludeDeleted configures the query-builder to return soft-deleted Users as well.
+// Note that it applies only to this query, and not to the edges it loads or traverses.
+func (uq *UserQuery) IncludeDeleted() *UserQuery {
+	uq.withDeleted = true
+	return uq
+}
+
+// SelectIDs returns a subquery that selects the IDs of the Users of the query. It can
+// be used in the InQuery and NotInQuery predicates of other queries (e.g. IDInQuery), and the query
+// should not be used after. The subquery is built with the context of the statement of the
+// outer query, and therefore, shares its operation, timeout and tracing.
+func (uq *UserQuery) SelectIDs() predicate.Subquery[int] {
+	return predicate.NewSubquery[int](func(ctx context.Context) (*sql.Selector, error) {
+		return uq.subquery(ctx, user.FieldID)
+	})
+}
+
+// subquery returns the selector of the query, that selects only the given column.
+func (uq *UserQuery) subquery(ctx context.Context, column string) (*sql.Selector, error) {
+	if err := uq.prepareQuery(ctx); err != nil {
+		return nil, err
+	}
+	selector := uq.sqlQuery(ctx)
+	selector.Select(selector.C(column))
+	return selector, nil
+}
+
+// Timeout sets the timeout of the query, including the eager-loading of its edges. It replaces
+// the default timeout of the client (see StatementTimeout), and applies to all the ways of
+// executing the query (e.g. Select, GroupBy, Iter and Project).
+//
+//	users, err := client.User.Query().
+//		Order(ent.Asc(user.FieldID)).
+//		Timeout(5 * time.Second).
+//		All(ctx)
+//
+func (uq *UserQuery) Timeout(d time.Duration) *UserQuery {
+	uq.timeout = d
+	return uq
+}
+
 // UserGroupBy is the group-by builder for User entities.
 type UserGroupBy struct {
 	config
 	selector
 	fields []string
 	fns    []AggregateFunc
+	// timeout of the query, inherited from the query-builder.
+	timeout time.Duration
 	// intermediate query (i.e. traversal path).
 	sql  *sql.Selector
 	path func(context.Context) (*sql.Selector, error)
@@ -533,6 +990,9 @@ func (ugb *UserGroupBy) Aggregate(fns ...AggregateFunc) *UserGroupBy {
 
 // Scan applies the group-by query and scans the result into the given value.
 func (ugb *UserGroupBy) Scan(ctx context.Context, v any) error {
+	ctx = newOperationContext(ctx, "User", "GroupBy")
+	ctx, cancel := newTimeoutContext(ctx, ugb.timeout)
+	defer cancel()
 	query, err := ugb.path(ctx)
 	if err != nil {
 		return err
@@ -597,6 +1057,9 @@ func (us *UserSelect) Scan(ctx context.Context, v any) error {
 }
 
 func (us *UserSelect) sqlScan(ctx context.Context, v any) error {
+	ctx = newOperationContext(ctx, "User", "Select")
+	ctx, cancel := newTimeoutContext(ctx, us.timeout)
+	defer cancel()
 	rows := &sql.Rows{}
 	query, args := us.sql.Query()
 	if err := us.driver.Query(ctx, query, args, rows); err != nil {
@@ -605,3 +1068,9 @@ func (us *UserSelect) sqlScan(ctx context.Context, v any) error {
 	defer rows.Close()
 	return sql.ScanSlice(rows, v)
 }
+
+// Modify adds a query modifier for attaching custom logic to queries.
+func (us *UserSelect) Modify(modifiers ...func(s *sql.Selector)) *UserSelect {
+	us.modifiers = append(us.modifiers, modifiers...)
+	return us
+}